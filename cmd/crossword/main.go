@@ -1,18 +1,24 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"log"
-	"math"
+	"net/http"
 	"os"
-	"sort"
 	"strings"
+	"time"
 
-	"flag"
-
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/config"
+	"github.com/Germanicus1/crizzcrozz/internal/crossword"
 	"github.com/Germanicus1/crizzcrozz/internal/generators"
-	"github.com/Germanicus1/crizzcrozz/internal/models"
+	"github.com/Germanicus1/crizzcrozz/internal/puzzle"
+	"github.com/Germanicus1/crizzcrozz/internal/render"
+	"github.com/Germanicus1/crizzcrozz/internal/server"
+	"github.com/Germanicus1/crizzcrozz/pkg/models"
 	"github.com/gocarina/gocsv"
 )
 
@@ -20,19 +26,26 @@ import (
 var ErrInvalidDimensions = errors.New("invalid board dimensions")
 
 func main() {
-	estimate, findOptimalSize, width, _, maxRetries, fileName := parseFlags()
-	// fileName := parseFlags()
-	if !findOptimalSize && !estimate && width == 1 {
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		serve(os.Args[2:])
+		return
+	}
+
+	opts, err := config.ParseFlags(os.Args[1:])
+	if err != nil {
+		os.Exit(2)
+	}
+	if !opts.FindOptimalSize && !opts.Estimate && opts.Width == 1 {
 		// FIXME: Improve error handling
 		fmt.Println("You need to specify a reasonable width for the board. Use the -f=<size> or -e=TRUE for estimating a size.")
 		return
 	}
 
-	fmt.Println("estimate:", estimate)
-	fmt.Println("findOptimalSize:", findOptimalSize)
+	fmt.Println("estimate:", opts.Estimate)
+	fmt.Println("findOptimalSize:", opts.FindOptimalSize)
 	// findOptimalSize = false
 
-	wordsAndHints, err := readWordsFromFile(fileName)
+	wordsAndHints, err := readWordsFromFile(opts.FileName)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			log.Fatalf("File does not exist: %v", err)
@@ -41,138 +54,236 @@ func main() {
 		}
 	}
 
-	cleanedWords := cleanWords(wordsAndHints)
-	sortedWords := sortWordsByLength(cleanedWords)
+	var dictionary []*models.WordsAndHints
+	if opts.Dictionary != "" {
+		dictionary, err = readWordsFromFile(opts.Dictionary)
+		if err != nil {
+			log.Fatalf("Failed to read the dictionary: %v", err)
+		}
+	}
 
-	var bestBoard *models.Board
+	var objective generators.Objective
+	if opts.Objective != "" {
+		objective, err = generators.ParseObjective(opts.Objective)
+		if err != nil {
+			fmt.Printf("%v.\n", err)
+			return
+		}
+	}
 
-	if estimate {
-		width = estimateInitialBoardSize(sortedWords)
+	var mask *board.Mask
+	if opts.Mask != "" {
+		mask, err = readMaskFromFile(opts.Mask)
+		if err != nil {
+			log.Fatalf("Failed to read the mask: %v", err)
+		}
 	}
-	bestBoard = createBoard(sortedWords, maxRetries, width)
-	if bestBoard == nil {
-		fmt.Println("No words could be placed. Try a larger board.")
+
+	var pinned []*models.PinnedWord
+	if opts.Pinned != "" {
+		pinned, err = readPinnedWordsFromFile(opts.Pinned)
+		if err != nil {
+			log.Fatalf("Failed to read the pinned words: %v", err)
+		}
+	}
+
+	board.Debug = opts.Debug
+
+	width, height := opts.Width, opts.Height
+	if opts.Estimate {
+		width, height = 0, 0 // Let the builder estimate the size.
+	}
+	ctx := context.Background()
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	started := time.Now()
+	buildOpts := crossword.Options{
+		Width:      width,
+		Height:     height,
+		Mask:       mask,
+		MaxRetries: opts.MaxRetries,
+		Generator:  opts.Generator,
+		Seed:       opts.Seed,
+		Dictionary: dictionary,
+		Symmetry:   generators.Symmetry(opts.Symmetry),
+		Objective:  objective,
+		Pinned:     pinned,
+		Crop:       opts.Crop,
+		Workers:    opts.Workers,
+		Budget:     generators.Budget{MaxNodes: opts.MaxNodes, MaxBacktracks: opts.MaxBacktracks},
+	}
+	var res *crossword.Result
+	if opts.FindOptimalSize {
+		res, err = findSmallestSize(ctx, wordsAndHints, buildOpts, opts.Aspect)
+	} else {
+		res, err = crossword.Build(ctx, wordsAndHints, buildOpts)
+	}
+	if errors.Is(err, generators.ErrUnknownGenerator) {
+		fmt.Printf("%v. Use one of: %s.\n", err, strings.Join(generators.Names, ", "))
 		return
 	}
+	if errors.Is(err, generators.ErrUnknownSymmetry) {
+		fmt.Printf("%v. Use one of: %s.\n", err, strings.Join(generators.SymmetryNames, ", "))
+		return
+	}
+	if errors.Is(err, generators.ErrInvalidPin) || errors.Is(err, generators.ErrInvalidSymmetry) {
+		fmt.Printf("%v.\n", err)
+		return
+	}
+	if errors.Is(err, crossword.ErrNoSizeFound) {
+		fmt.Printf("%v. Try a longer -timeout.\n", err)
+		return
+	}
+	if err != nil {
+		fmt.Printf("%v. Try a larger board.\n", err)
+		return
+	}
+	elapsed := time.Since(started)
+	bestBoard := res.Board
+	if res.Reason == generators.Complete {
+		fmt.Println("Successfully generated crossword.")
+	} else {
+		fmt.Printf("Could not fit all words (%s). Showing best attempt:\n", res.Reason)
+	}
 
 	fmt.Printf("Board size: %dx%d | Words placed: %d/%d\n", bestBoard.Bounds.Width(), bestBoard.Bounds.Height(), bestBoard.BestWordCount, bestBoard.TotalWords)
 	bestBoard.PrintBestSolution()
+	fmt.Printf("Grid: %dx%d | Density: %.0f%% of the cells hold letters\n", res.Size.Width, res.Size.Height, res.Size.Density*100)
+	fmt.Printf("Score: %s\n", res.Score)
+	fmt.Printf("Generator: %s | Time: %s | Placements: %d | Backtracks: %d\n", opts.Generator, elapsed.Round(time.Millisecond), res.Nodes, res.Backtracks)
+	if res.Reason != generators.Complete {
+		fmt.Printf("Stopped early: %s.\n", res.Reason)
+	}
+	if opts.Symmetry != string(generators.NoSymmetry) {
+		fmt.Printf("Symmetry: %s | %.0f%% of the letters are mirrored\n", opts.Symmetry, res.Symmetry*100)
+	}
+	fmt.Printf("Seed: %d (use -seed=%d -workers=1 to generate this puzzle again)\n", bestBoard.Seed, bestBoard.Seed)
 
-	// fmt.Println("CELL (14,13):", bestBoard.Cells[14][13].Filled)
-
-	// TODO: Implement discovery of smallest board size possible with all the
-	// words placed.
-
-	// board, bestSize, err := findOptimalBoardSize(words, maxRetries)
-	// if err != nil {
-	// 	log.Fatalf("Failed to generate crossword: %v", err)
-	// }
-
-	// fmt.Printf("Optimal board size found: %dx%d\n", bestSize, bestSize)
-	// board.PrintBestSolution()
-}
-
-// parseFlags returns the filename of the csv-file to parse.
-func parseFlags() (bool, bool, int, int, int, string) {
-	var fileName string
-	var width, height, maxRetries int
-	var findOptimalSize, estimate bool
-	flag.StringVar(&fileName, "f", "vocabulary.csv", "Specify the file with the words and hints. Defaults to vocabulary.csv.")
-	flag.IntVar(&width, "w", 1, "Specify the width of the board. Defaults to 1.")
-	flag.IntVar(&height, "h", 1, "Specify the width of the board. Defaults to 1")
-	flag.IntVar(&maxRetries, "r", 1, "Specify the max number of retries to build the crossword. Defaults to 1.")
-	flag.BoolVar(&findOptimalSize, "o", false, "Decide if the generator has to find th optimum board size. Default FALSE.")
-	flag.BoolVar(&estimate, "e", true, "Decide if the program can calculate an estimated board size. Default FALSE.")
-	flag.Parse()
+	if opts.JSONFileName != "" || opts.PuzFileName != "" || opts.IPuzFileName != "" || opts.SVGFileName != "" || opts.PDFFileName != "" || opts.HTMLFileName != "" {
+		if err := savePuzzle(bestBoard, opts); err != nil {
+			log.Fatalf("Failed to save puzzle: %v", err)
+		}
+	}
 
-	return estimate, findOptimalSize, width, height, maxRetries, fileName
+	// fmt.Println("CELL (14,13):", bestBoard.Cells[14][13].Filled)
 }
 
-// setUpBoard initializes a crossword board with given dimensions and a
-// list of words. It returns a pointer to the created board or an error
-// if the board cannot be created.
-func setUpBoard(width, height int, wordCount int) (*models.Board, error) {
-	if width <= 0 || height <= 0 {
-		return nil, fmt.Errorf("invalid board dimensions (width: %d, height: %d)", width, height)
+// findSmallestSize searches for the smallest board that fits all words and
+// prints what each size tried achieved.
+func findSmallestSize(ctx context.Context, wordsAndHints []*models.WordsAndHints, opts crossword.Options, aspect float64) (*crossword.Result, error) {
+	res, err := crossword.FindSmallestSize(ctx, wordsAndHints, opts, aspect)
+	if res != nil {
+		fmt.Println("Board sizes tried:")
+		for _, t := range res.Trials {
+			fmt.Printf("  %dx%d: %d/%d words (%s)", t.Width, t.Height, t.Placed, t.Total, t.Reason)
+			if t.Reason == generators.Complete {
+				fmt.Printf(", letters in %dx%d", t.Grid.Width, t.Grid.Height)
+			}
+			if t.Reused > 0 {
+				fmt.Printf(", started from %d words of the last layout", t.Reused)
+			}
+			fmt.Println()
+		}
 	}
-
-	bounds, err := models.NewBoundsRectangle(width, height)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create board boundaries: %w", err)
+		return nil, err
 	}
+	fmt.Printf("Smallest board found: %dx%d\n", res.Board.Bounds.Width(), res.Board.Bounds.Height())
+	return res.Result, nil
+}
 
-	fileWriter := &models.OSFileWriter{} // Creating an instance of FileWriter
-	board := models.NewBoard(bounds, wordCount, fileWriter)
-	if board == nil {
-		return nil, fmt.Errorf("failed to initialize the crossword board")
+// serve runs the HTTP API until the process is stopped.
+func serve(args []string) {
+	opts, err := config.ParseServeFlags(args)
+	if err != nil {
+		os.Exit(2)
 	}
-	board.Save()
-	return board, nil
+	s := server.New(opts.Timeout, opts.MaxConcurrent)
+	fmt.Printf("Serving the crossword API on http://%s\n", opts.Addr)
+	log.Fatal(http.ListenAndServe(opts.Addr, s.Handler()))
 }
 
-// generateCrossword tries to populate the crossword board with words.
-// It returns an error if the crossword generation fails.
-// func generateCrossword(b *models.Board, words []string) error {
-// 	newPool := models.NewPool() // Creates a new pool to hold words.
-// 	newPool.LoadWords(words)    // Loads words into the pool.
-
-// 	generator := generators.NewAsymmetricalGenerator(b, newPool) // Initializes a new crossword generator.
-
-//		err := generator.Generate() // Attempts to generate the crossword.
-//		if err != nil {
-//			return err // Returns an error if generation is unsuccessful.
-//		}
-//		return nil
-//	}
-func generateCrossword(b *models.Board, words []string, maxRetries int) error {
-	newPool := models.NewPool()
-	newPool.LoadWords(words)
-
-	generator := generators.NewAsymmetricalGenerator(b, newPool)
-
-	var bestBoard *models.Board
-	maxWordsPlaced := 0
-
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		// REM fmt.Printf("Attempt %d/%d to generate crossword...\n", attempt+1, maxRetries)
-
-		err := generator.Generate()
-		if err == nil { // Success: all words fit
-			fmt.Println("Successfully generated crossword.")
-			return nil
+// savePuzzle writes the best solution of the board to the puzzle files
+// requested in opts.
+func savePuzzle(b *board.Board, opts *config.Options) error {
+	p, err := puzzle.FromBoard(b)
+	if err != nil {
+		return err
+	}
+	p.Title = opts.Title
+	p.Author = opts.Author
+	p.Copyright = opts.Copyright
+
+	fw := &board.OSFileWriter{}
+	if opts.JSONFileName != "" {
+		if err := p.Save(opts.JSONFileName, fw); err != nil {
+			return err
 		}
-
-		// Track the best attempt
-		if b.WordCount > maxWordsPlaced {
-			maxWordsPlaced = b.WordCount
-			bestBoard = b
+		fmt.Printf("Puzzle saved to %s\n", opts.JSONFileName)
+	}
+	if opts.PuzFileName != "" {
+		if err := p.SavePuz(opts.PuzFileName, fw); err != nil {
+			return err
 		}
-
-		// REM fmt.Printf("Retry %d/%d: Words placed: %d/%d\n", attempt+1, maxRetries, b.WordCount, len(words))
+		fmt.Printf("Puzzle saved to %s\n", opts.PuzFileName)
 	}
-
-	// Ensure something is printed even if all retries fail
-	if bestBoard != nil {
-		fmt.Println("Could not fit all words. Showing best attempt:")
-		// bestBoard.PrintBestSolution()
-		return fmt.Errorf("crossword generation failed after %d retries", maxRetries)
+	if opts.IPuzFileName != "" {
+		if err := p.SaveIPuz(opts.IPuzFileName, fw); err != nil {
+			return err
+		}
+		fmt.Printf("Puzzle saved to %s\n", opts.IPuzFileName)
 	}
-
-	fmt.Println("No words could be placed. Try increasing the board size.")
-	return fmt.Errorf("crossword generation failed completely")
+	if opts.SVGFileName != "" {
+		var buf bytes.Buffer
+		if err := render.SVG(&buf, p, render.SVGOptions{Solution: opts.Answers}); err != nil {
+			return err
+		}
+		if err := fw.WriteFile(opts.SVGFileName, buf.Bytes(), 0644); err != nil {
+			return err
+		}
+		fmt.Printf("Puzzle saved to %s\n", opts.SVGFileName)
+	}
+	if opts.PDFFileName != "" {
+		paper, err := render.PaperByName(opts.Paper)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if err := render.PDF(&buf, p, render.PDFOptions{Paper: paper, Answers: opts.Answers}); err != nil {
+			return err
+		}
+		if err := fw.WriteFile(opts.PDFFileName, buf.Bytes(), 0644); err != nil {
+			return err
+		}
+		fmt.Printf("Puzzle saved to %s\n", opts.PDFFileName)
+	}
+	if opts.HTMLFileName != "" {
+		var buf bytes.Buffer
+		if err := render.HTML(&buf, p); err != nil {
+			return err
+		}
+		if err := fw.WriteFile(opts.HTMLFileName, buf.Bytes(), 0644); err != nil {
+			return err
+		}
+		fmt.Printf("Puzzle saved to %s\n", opts.HTMLFileName)
+	}
+	return nil
 }
 
 // readWordsFromFile reads words and their hints from a specified CSV
 // file. It returns a slice of wordsAndHints structs or an error if the
 // file cannot be read.
-func readWordsFromFile(fileName string) ([]*wordsAndHints, error) {
+func readWordsFromFile(fileName string) ([]*models.WordsAndHints, error) {
 	csvFile, err := os.OpenFile(fileName, os.O_RDWR, os.ModePerm) // Opens the CSV file for reading.
 	if err != nil {
 		return nil, err // Returns an error if the file cannot be opened.
 	}
 	defer csvFile.Close() // Ensures the file is closed after the operation.
 
-	var wordsAndHints []*wordsAndHints
+	var wordsAndHints []*models.WordsAndHints
 	if err := gocsv.UnmarshalFile(csvFile, &wordsAndHints); err != nil {
 		return nil, err // Returns an error if the CSV data cannot be parsed.
 	}
@@ -181,71 +292,37 @@ func readWordsFromFile(fileName string) ([]*wordsAndHints, error) {
 	return wordsAndHints, nil
 }
 
-func sortWordsByLength(words []string) []string {
-	sort.Slice(words, func(j, i int) bool {
-		return len(words[i]) < len(words[j]) // Sorts words by length.
-	})
-	return words
-}
-
-func findOptimalBoardSize(words []string, maxRetries int) (*models.Board, int, error) {
-	low := estimateInitialBoardSize(words) / 2 // Start smaller
-	high := low * 3                            // Start with a reasonable max size
-
-	var bestBoard *models.Board
-	var bestSize int
-
-	for low <= high {
-		mid := (low + high) / 2
-		fmt.Printf("Trying board size: %dx%d\n", mid, mid)
-
-		board, err := setUpBoard(mid, mid, len(words))
-		if err != nil {
-			return nil, 0, err
-		}
-
-		err = generateCrossword(board, words, maxRetries)
-		if err == nil { // Success: all words fit
-			bestBoard = board
-			bestSize = mid
-			high = mid - 1 // Try a smaller size
-		} else {
-			low = mid + 1 // Increase the size
-		}
+// readPinnedWordsFromFile reads the words placed by hand from a specified
+// CSV file.
+func readPinnedWordsFromFile(fileName string) ([]*models.PinnedWord, error) {
+	csvFile, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
 	}
+	defer csvFile.Close()
 
-	if bestBoard == nil {
-		return nil, 0, fmt.Errorf("could not fit words on any reasonable board size")
+	var pinned []*models.PinnedWord
+	if err := gocsv.UnmarshalFile(csvFile, &pinned); err != nil {
+		return nil, err
 	}
-
-	return bestBoard, bestSize, nil
+	return pinned, nil
 }
 
-// func estimateInitialBoardSize(words []string) int {
-// 	// totalLength := 0
-// 	// longestWord := 0
-
-// 	// for _, word := range words {
-// 	// 	wordLen := len([]rune(word))
-// 	// 	totalLength += wordLen
-// 	// 	if wordLen > longestWord {
-// 	// 		longestWord = wordLen
-// 	// 	}
-// 	// }
-
-// 	// // Start with something proportional to total word length
-// 	// estimatedSize := int(float64(totalLength) * 0.7) // 70% of total length
-// 	// if estimatedSize < longestWord {                 // Ensure at least the longest word fits
-// 	// 	estimatedSize = longestWord + 2
-// 	// }
-
-// 	return int(float64(len([]rune(words[0]))) * 1.5)
-// }
+// readMaskFromFile reads the shape of a board from a file as drawn for
+// board.ParseMask.
+func readMaskFromFile(fileName string) (*board.Mask, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return board.ParseMask(f)
+}
 
 // printBoard outputs the current state of the crossword board to the
 // console. It marks filled cells with their respective characters and
 // empty cells with a dot.
-func printBoard(b *models.Board) {
+func printBoard(b *board.Board) {
 	for _, row := range b.Cells {
 		for _, cell := range row {
 			if cell.Filled {
@@ -257,78 +334,3 @@ func printBoard(b *models.Board) {
 		fmt.Println() // Ensures each row of the board is printed on a new line.
 	}
 }
-
-func estimateInitialBoardSize(words []string) int {
-	wordCount := len(words)
-	if wordCount == 0 {
-		return 10 // Default minimum size
-		// FIXME: This should probably retrun an error.
-	}
-
-	longestWord := 0
-	totalLength := 0
-
-	for _, word := range words {
-		wordLen := len([]rune(word))
-		totalLength += wordLen
-		if wordLen > longestWord {
-			longestWord = wordLen
-		}
-	}
-
-	averageWordLength := totalLength / wordCount
-
-	// Adjust density factor based on expected intersection
-	densityFactor := 1.2 // Increase density factor for better spacing
-
-	// Adjust padding dynamically based on longest word and total words
-	padding := int(math.Max(float64(longestWord)*0.3, float64(wordCount)*0.3)) // More words → more padding
-
-	// Estimate board size using an improved formula
-	estimatedSize := int(math.Sqrt(float64(wordCount) * float64(averageWordLength) * densityFactor))
-
-	// Ensure it's at least large enough for the longest word + padding
-	if estimatedSize < longestWord+padding {
-		estimatedSize = longestWord + padding
-	}
-
-	// Add extra space for flexibility
-	// estimatedSize += 3
-
-	return estimatedSize
-}
-
-func cleanWords(wh []*wordsAndHints) []string {
-	var words []string
-	for _, v := range wh {
-		cleanWord := strings.TrimSpace(v.Word)
-		words = append(words, cleanWord)
-	}
-	return words
-}
-
-func createBoard(sortedWords []string, maxRetries, width int) *models.Board {
-	height := width // Always a square board
-
-	// Track the best attempt
-	var bestBoard *models.Board
-	maxWordsPlaced := 0
-
-	for attempt := 0; attempt < maxRetries; attempt++ { // Limit attempts to prevent infinite loops
-		tempBoard, _ := setUpBoard(width, height, len(sortedWords)) // Create a fresh board
-		err := generateCrossword(tempBoard, sortedWords, maxRetries)
-
-		if err == nil { // Success, all words fit
-			bestBoard = tempBoard
-			break
-		}
-
-		// Update bestBoard if this attempt placed more words
-		if tempBoard.WordCount > maxWordsPlaced {
-			maxWordsPlaced = tempBoard.WordCount
-			bestBoard = tempBoard
-		}
-	}
-
-	return bestBoard
-}
@@ -2,21 +2,12 @@ package main
 
 import (
 	"os"
-	"reflect"
 	"testing"
 
-	"github.com/Germanicus1/crizzcrozz/internal/parser"
+	"github.com/Germanicus1/crizzcrozz/internal/parse"
+	"github.com/Germanicus1/crizzcrozz/pkg/models"
 )
 
-func TestSortWordsByLength(t *testing.T) {
-	words := []string{"zoo", "österreich", "ärger", "überraschung"}
-	result := sortWordsByLength(words)
-	want := []string{"überraschung", "österreich", "ärger", "zoo"}
-	if !reflect.DeepEqual(result, want) {
-		t.Errorf("Incorrect result, got: %s, want: %s", result, want)
-	}
-}
-
 // Utility function to create a mock CSV file for testing.
 func createMockCSVFile(content string) (string, func(), error) {
 	file, err := os.CreateTemp("", "mock.csv")
@@ -40,7 +31,7 @@ func TestReadWordsFromFile_Success(t *testing.T) {
 	defer cleanup()
 
 	// Test the function.
-	result, err := parser.ReadWordsFromFile(fileName)
+	result, err := parse.ReadWordsFromFile(fileName)
 	if err != nil {
 		t.Fatalf("Unexpected error: %s", err)
 	}
@@ -51,7 +42,7 @@ func TestReadWordsFromFile_Success(t *testing.T) {
 	}
 
 	// Expected result.
-	expected := []*wordsAndHints{
+	expected := []*models.WordsAndHints{
 		{Word: "apple", Hint: "Fruit"},
 		{Word: "sky", Hint: "Blue"},
 	}
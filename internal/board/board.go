@@ -5,12 +5,27 @@ import (
 	"fmt"
 	"os"
 	"strings"
+
+	"github.com/Germanicus1/crizzcrozz/internal/words"
 )
 
+// Debug turns on the trace of every placement, removal and check on the
+// boards, and of the searches of the generators, on standard output. It is
+// off by default, so builds stay quiet; set it before generating.
+var Debug bool
+
+// debugf prints the trace message if Debug is on.
+func debugf(format string, a ...any) {
+	if Debug {
+		fmt.Printf(format, a...)
+	}
+}
+
 type PlacedWord struct {
 	Start     Location
 	Direction Direction
 	Word      string
+	Hint      string
 }
 
 // Board represents the entire state of the crossword puzzle.
@@ -21,8 +36,10 @@ type Board struct {
 	WordList    map[string]bool
 	WordCount   int
 	TotalWords  int
-	Pool        *Pool
+	Pool        *words.Pool
 	FileWriter  FileWriter `json:"-"` // Exclude from JSON serialization. Dependency injection for testing file I/O
+	Seed        int64      // The seed of the random choices that built the board, zero if there were none.
+	Mask        *Mask      `json:"-"` // The cells letters may go in; all cells if nil. Set it with SetMask.
 
 	// Track the best solution found
 	BestBoard       [][]*Cell
@@ -60,6 +77,22 @@ func NewBoard(bounds *Bounds, totalWords int, filewriter FileWriter) *Board {
 	}
 }
 
+// SetMask limits the letters of the board to the cells the mask allows. The
+// mask must have the size of the board and is shared, not copied, so it must
+// not change afterwards.
+func (b *Board) SetMask(m *Mask) error {
+	if m != nil && (m.Width() != b.Bounds.Width() || m.Height() != b.Bounds.Height()) {
+		return fmt.Errorf("mask of %dx%d cells does not match the board of %dx%d cells", m.Width(), m.Height(), b.Bounds.Width(), b.Bounds.Height())
+	}
+	b.Mask = m
+	return nil
+}
+
+// Allowed reports whether the cell at x, y may hold a letter.
+func (b *Board) Allowed(x, y int) bool {
+	return b.Mask == nil || b.Mask.Allowed(x, y)
+}
+
 // Save converts the Board struct to JSON and writes it to the file structure.
 //
 // Returns: Error if marshalling or file writing did not work; nil otherwise.
@@ -125,7 +158,7 @@ func (b *Board) CanPlaceWordAt(start Location, word string, direction Direction)
 	runes := []rune(word)
 
 	// Step 1: Ensure word fits within board bounds
-	if !b.isPlacementWithinBounds(start, len(runes), deltaX, deltaY) {
+	if !b.isPlacementWithinBounds(start, len(runes), deltaX, deltaY) || !b.isPlacementAllowed(start, len(runes), deltaX, deltaY) {
 		return false
 	}
 
@@ -154,7 +187,7 @@ func (b *Board) CanPlaceWordAt(start Location, word string, direction Direction)
 
 	// 🚨 NEW: Ensure the cells before and after are not locked
 	if !b.isPlacementIsolated(start, len(runes), deltaX, deltaY) {
-		fmt.Printf("❌ ERROR: Word '%s' at (%d, %d) is not isolated\n", word, start.X, start.Y)
+		debugf("❌ ERROR: Word '%s' at (%d, %d) is not isolated\n", word, start.X, start.Y)
 		return false
 	}
 
@@ -197,21 +230,21 @@ func (b *Board) isPlacementIsolated(start Location, lettersInWord, deltaX, delta
 	// Check the cell before the word
 	xBefore, yBefore := start.X-deltaX, start.Y-deltaY
 	if !isOutOfBound(xBefore, yBefore, b) && isCellFilled(xBefore, yBefore, b) {
-		fmt.Printf("❌ ERROR: Cell BEFORE word at (%d, %d) is occupied!\n", xBefore, yBefore)
+		debugf("❌ ERROR: Cell BEFORE word at (%d, %d) is occupied!\n", xBefore, yBefore)
 		isIsolated = false
 	}
 
 	// Check the cell after the word
 	xAfter, yAfter := start.X+lettersInWord*deltaX, start.Y+lettersInWord*deltaY
 	if !isOutOfBound(xAfter, yAfter, b) && isCellFilled(xAfter, yAfter, b) {
-		fmt.Printf("❌ ERROR: Cell AFTER word at (%d, %d) is occupied!\n", xAfter, yAfter)
+		debugf("❌ ERROR: Cell AFTER word at (%d, %d) is occupied!\n", xAfter, yAfter)
 		isIsolated = false
 	}
 
 	if isIsolated {
-		fmt.Printf("✅ Word at (%d, %d) is properly isolated.\n", start.X, start.Y)
+		debugf("✅ Word at (%d, %d) is properly isolated.\n", start.X, start.Y)
 	} else {
-		fmt.Printf("❌ Word at (%d, %d) is NOT isolated! Placement should be blocked.\n", start.X, start.Y)
+		debugf("❌ Word at (%d, %d) is NOT isolated! Placement should be blocked.\n", start.X, start.Y)
 	}
 
 	return isIsolated
@@ -242,6 +275,8 @@ func (b *Board) canPlaceLetters(start Location, word string, deltaX, deltaY int)
 			cellIsIntersection = true
 			intersectionCount++
 			consecutiveIntersections++
+		} else if b.Cells[y][x].Filled {
+			return false // A different letter is in the way.
 		} else {
 			consecutiveIntersections = 0 // Reset count if not consecutive
 		}
@@ -303,6 +338,57 @@ func (b *Board) isPlacementWithinBounds(start Location, charsCount int, deltaX,
 	return !isOutOfBound(x, y, b)
 }
 
+// Fits reports whether a word placed at start in the given direction lies
+// completely on the board. Unlike CanPlaceWordAt it ignores the letters
+// already on the board.
+func (b *Board) Fits(start Location, word string, direction Direction) bool {
+	deltaX, deltaY := getDirectionDeltas(direction)
+	n := len([]rune(word))
+	return !isOutOfBound(start.X, start.Y, b) && b.isPlacementWithinBounds(start, n, deltaX, deltaY) && b.isPlacementAllowed(start, n, deltaX, deltaY)
+}
+
+// CanPinWordAt reports whether a word can be placed at start like
+// CanPlaceWordAt, except that it does not have to cross a letter on the
+// board, so words can be placed by hand before the others.
+func (b *Board) CanPinWordAt(start Location, word string, direction Direction) bool {
+	if !b.Fits(start, word, direction) {
+		return false
+	}
+	deltaX, deltaY := getDirectionDeltas(direction)
+	runes := []rune(word)
+	consecutiveIntersections := 0
+	for i, r := range runes {
+		x := start.X + i*deltaX
+		y := start.Y + i*deltaY
+		if b.Cells[y][x].Filled {
+			consecutiveIntersections++
+			if b.Cells[y][x].Character != string(r) || consecutiveIntersections >= 2 {
+				return false
+			}
+			continue
+		}
+		consecutiveIntersections = 0
+		if isParallelPlacement(x, y, direction, b) {
+			return false
+		}
+	}
+	return b.isPlacementIsolated(start, len(runes), deltaX, deltaY)
+}
+
+// isPlacementAllowed reports whether the mask of the board allows letters
+// in all cells of a word at start. The word must lie on the board.
+func (b *Board) isPlacementAllowed(start Location, lettersInWord, deltaX, deltaY int) bool {
+	if b.Mask == nil {
+		return true
+	}
+	for i := 0; i < lettersInWord; i++ {
+		if !b.Mask.Allowed(start.X+i*deltaX, start.Y+i*deltaY) {
+			return false
+		}
+	}
+	return true
+}
+
 // isParallelPlacement checks if there are already filled cells directly
 // adjacent to a given cell in the board depending on the orientation of the
 // word being placed. This function is used to prevent adjacent parallel words
@@ -423,7 +509,14 @@ func getDirectionDeltas(direction Direction) (int, int) {
 //
 // REM debugging
 func (b *Board) PlaceWordAt(start Location, word string, direction Direction) error {
-	fmt.Printf("📌 Placing word: %s at (%d, %d) %v\n", word, start.X, start.Y, direction)
+	return b.PlaceEntryAt(start, words.Entry{Word: word}, direction)
+}
+
+// PlaceEntryAt places the word of entry like PlaceWordAt and keeps its hint
+// with the placed word.
+func (b *Board) PlaceEntryAt(start Location, entry words.Entry, direction Direction) error {
+	word := entry.Word
+	debugf("📌 Placing word: %s at (%d, %d) %v\n", word, start.X, start.Y, direction)
 
 	deltaX, deltaY := getDirectionDeltas(direction)
 	runes := []rune(word)
@@ -437,13 +530,13 @@ func (b *Board) PlaceWordAt(start Location, word string, direction Direction) er
 		cell.Filled = true
 		cell.UsageCount++
 
-		fmt.Printf("  ✅ Placed '%s' at (%d, %d), UsageCount: %d\n", string(r), x, y, cell.UsageCount)
+		debugf("  ✅ Placed '%s' at (%d, %d), UsageCount: %d\n", string(r), x, y, cell.UsageCount)
 	}
 
 	// 🚨 NEW: Lock cells before and after the word
 	b.lockAdjacentCells(start, len(runes), deltaX, deltaY)
 
-	b.PlacedWords = append(b.PlacedWords, PlacedWord{Start: start, Direction: direction, Word: word})
+	b.PlacedWords = append(b.PlacedWords, PlacedWord{Start: start, Direction: direction, Word: word, Hint: entry.Hint})
 	b.WordCount++
 	return nil
 }
@@ -489,7 +582,7 @@ func (b *Board) IsComplete() bool {
 
 // REM debugging
 func (b *Board) RemoveWord(start Location, word string, direction Direction) {
-	fmt.Printf("🔄 Backtracking: Removing word %s from (%d, %d) %v\n", word, start.X, start.Y, direction)
+	debugf("🔄 Backtracking: Removing word %s from (%d, %d) %v\n", word, start.X, start.Y, direction)
 
 	deltaX, deltaY := getDirectionDeltas(direction)
 	runes := []rune(word)
@@ -524,20 +617,63 @@ func (b *Board) SaveBestSolution() {
 	b.BestWordCount = b.WordCount
 
 	// Deep copy the board cells
-	b.BestBoard = make([][]*Cell, len(b.Cells))
-	for i := range b.Cells {
-		b.BestBoard[i] = make([]*Cell, len(b.Cells[i]))
-		for j := range b.Cells[i] {
-			cellCopy := *b.Cells[i][j] // Copy struct
-			b.BestBoard[i][j] = &cellCopy
-		}
-	}
+	b.BestBoard = cloneCells(b.Cells)
 
 	// Copy the placed words
 	b.BestPlacedWords = make([]PlacedWord, len(b.PlacedWords))
 	copy(b.BestPlacedWords, b.PlacedWords) //Directly assiging b.Cells, later changes will affect the stored best board.
 }
 
+// Clone returns an independent copy of the board. The copy shares no cells,
+// slices or maps with the board, so both can be changed, for example by two
+// generators running at the same time, without affecting each other. Only
+// the FileWriter and the Mask, which does not change, are shared.
+func (b *Board) Clone() *Board {
+	clone := &Board{
+		Bounds:        NewBounds(b.Bounds.TopLeft, b.Bounds.BottomRight),
+		Cells:         cloneCells(b.Cells),
+		WordCount:     b.WordCount,
+		TotalWords:    b.TotalWords,
+		FileWriter:    b.FileWriter,
+		Seed:          b.Seed,
+		Mask:          b.Mask,
+		BestWordCount: b.BestWordCount,
+	}
+	if b.PlacedWords != nil {
+		clone.PlacedWords = append([]PlacedWord(nil), b.PlacedWords...)
+	}
+	if b.WordList != nil {
+		clone.WordList = make(map[string]bool, len(b.WordList))
+		for word, ok := range b.WordList {
+			clone.WordList[word] = ok
+		}
+	}
+	if b.Pool != nil {
+		clone.Pool = b.Pool.Clone()
+	}
+	if b.BestBoard != nil {
+		clone.BestBoard = cloneCells(b.BestBoard)
+	}
+	if b.BestPlacedWords != nil {
+		clone.BestPlacedWords = append([]PlacedWord(nil), b.BestPlacedWords...)
+	}
+	return clone
+}
+
+// cloneCells returns a deep copy of a grid of cells.
+func cloneCells(cells [][]*Cell) [][]*Cell {
+	clone := make([][]*Cell, len(cells))
+	for i := range cells {
+		clone[i] = make([]*Cell, len(cells[i]))
+		for j, cell := range cells[i] {
+			if cell != nil {
+				clone[i][j] = cell.Clone()
+			}
+		}
+	}
+	return clone
+}
+
 // PrintBestSolution outputs the crossword board to the console. It marks filled
 // cells with their respective characters and empty cells with a dot.
 // func (b *Board) PrintBestSolution() {
@@ -601,14 +737,14 @@ func (b *Board) lockAdjacentCells(start Location, lettersInWord, deltaX, deltaY
 	xBefore, yBefore := start.X-deltaX, start.Y-deltaY
 	if !isOutOfBound(xBefore, yBefore, b) {
 		b.Cells[yBefore][xBefore].LockCount++
-		fmt.Printf("🔒 Locking cell before word at (%d, %d), LockCount: %d\n", xBefore, yBefore, b.Cells[yBefore][xBefore].LockCount)
+		debugf("🔒 Locking cell before word at (%d, %d), LockCount: %d\n", xBefore, yBefore, b.Cells[yBefore][xBefore].LockCount)
 	}
 
 	// Lock the cell after the word
 	xAfter, yAfter := start.X+lettersInWord*deltaX, start.Y+lettersInWord*deltaY
 	if !isOutOfBound(xAfter, yAfter, b) {
 		b.Cells[yAfter][xAfter].LockCount++
-		fmt.Printf("🔒 Locking cell after word at (%d, %d), LockCount: %d\n", xAfter, yAfter, b.Cells[yAfter][xAfter].LockCount)
+		debugf("🔒 Locking cell after word at (%d, %d), LockCount: %d\n", xAfter, yAfter, b.Cells[yAfter][xAfter].LockCount)
 	}
 }
 
@@ -617,13 +753,13 @@ func (b *Board) unlockAdjacentCells(start Location, lettersInWord, deltaX, delta
 	xBefore, yBefore := start.X-deltaX, start.Y-deltaY
 	if !isOutOfBound(xBefore, yBefore, b) {
 		b.Cells[yBefore][xBefore].LockCount--
-		fmt.Printf("🔓 Unlocking cell before word at (%d, %d), LockCount: %d\n", xBefore, yBefore, b.Cells[yBefore][xBefore].LockCount)
+		debugf("🔓 Unlocking cell before word at (%d, %d), LockCount: %d\n", xBefore, yBefore, b.Cells[yBefore][xBefore].LockCount)
 	}
 
 	// Unlock the cell after the word
 	xAfter, yAfter := start.X+lettersInWord*deltaX, start.Y+lettersInWord*deltaY
 	if !isOutOfBound(xAfter, yAfter, b) {
 		b.Cells[yAfter][xAfter].LockCount--
-		fmt.Printf("🔓 Unlocking cell after word at (%d, %d), LockCount: %d\n", xAfter, yAfter, b.Cells[yAfter][xAfter].LockCount)
+		debugf("🔓 Unlocking cell after word at (%d, %d), LockCount: %d\n", xAfter, yAfter, b.Cells[yAfter][xAfter].LockCount)
 	}
 }
@@ -0,0 +1,37 @@
+package board_test
+
+import (
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+)
+
+func TestBoard_CanPlaceWordAt(t *testing.T) {
+	bounds, err := board.NewBoundsRectangle(9, 9)
+	if err != nil {
+		t.Fatalf("Failed to create bounds: %s", err)
+	}
+	b := board.NewBoard(bounds, 3, &board.OSFileWriter{})
+	b.PlaceWordAt(board.Location{X: 1, Y: 4}, "schule", board.Across)
+
+	tests := []struct {
+		name      string
+		start     board.Location
+		word      string
+		direction board.Direction
+		want      bool
+	}{
+		{"crossing", board.Location{X: 3, Y: 2}, "bahn", board.Down, true},
+		{"apart", board.Location{X: 0, Y: 0}, "see", board.Across, false},
+		{"over a different letter", board.Location{X: 3, Y: 4}, "katze", board.Down, false},
+		{"over a shorter word", board.Location{X: 0, Y: 4}, "ostereier", board.Across, false},
+		{"crossing and over a different letter", board.Location{X: 1, Y: 4}, "sahne", board.Across, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.CanPlaceWordAt(tt.start, tt.word, tt.direction); got != tt.want {
+				t.Errorf("Incorrect result, got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package board_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+)
+
+func TestBoard_Clone(t *testing.T) {
+	bounds, err := board.NewBoundsRectangle(5, 3)
+	if err != nil {
+		t.Fatalf("Failed to create bounds: %s", err)
+	}
+	b := board.NewBoard(bounds, 2, &board.OSFileWriter{})
+	b.Pool = words.NewPool()
+	b.Pool.LoadWords([]string{"haus", "see"})
+	b.PlaceWordAt(board.Location{X: 0, Y: 0}, "haus", board.Across)
+	b.SaveBestSolution()
+
+	clone := b.Clone()
+	if !reflect.DeepEqual(clone, b) {
+		t.Fatalf("Clone differs from the board, got: %+v, want: %+v", clone, b)
+	}
+
+	// Changing the clone must leave the board as it was.
+	clone.PlaceWordAt(board.Location{X: 3, Y: 0}, "see", board.Down)
+	clone.SaveBestSolution()
+	clone.Bounds.BottomRight.X++
+	clone.Pool.LoadWords([]string{"eis"})
+
+	if b.WordCount != 1 || len(b.PlacedWords) != 1 || b.BestWordCount != 1 || len(b.BestPlacedWords) != 1 {
+		t.Errorf("Board words changed with the clone, got: %d placed, %d best", b.WordCount, b.BestWordCount)
+	}
+	if b.Cells[1][3].Filled || b.BestBoard[1][3].Filled {
+		t.Errorf("Board cells changed with the clone")
+	}
+	if b.Cells[0][3].UsageCount != 1 {
+		t.Errorf("Shared cell changed with the clone, got usage: %d, want: 1", b.Cells[0][3].UsageCount)
+	}
+	if b.Bounds.Width() != 5 {
+		t.Errorf("Board bounds changed with the clone, got width: %d, want: 5", b.Bounds.Width())
+	}
+	if len(b.Pool.Entries) != 2 || b.Pool.Exists("eis") {
+		t.Errorf("Board pool changed with the clone, got: %v", b.Pool.Entries)
+	}
+}
@@ -0,0 +1,87 @@
+package board
+
+import "sort"
+
+// Clue is a numbered entry of the best solution: a placed word together with
+// the number printed in its first cell and its hint.
+type Clue struct {
+	Number    int
+	Start     Location
+	Direction Direction
+	Word      string
+	Hint      string
+}
+
+// Clues holds the numbered entries of the best solution. Both lists are
+// sorted by number.
+type Clues struct {
+	Across []Clue
+	Down   []Clue
+}
+
+// Clues numbers the words of the best solution, see NewClues.
+func (b *Board) Clues() Clues {
+	return NewClues(b.BestPlacedWords)
+}
+
+// NewClues numbers the placed words the way printed crosswords do: their
+// first cells are numbered in reading order, left to right and top to
+// bottom, and a cell starting both an across and a down word gets a single
+// number. Every clue keeps the hint of its placed word.
+func NewClues(words []PlacedWord) Clues {
+	placed := make([]PlacedWord, len(words))
+	copy(placed, words)
+	sort.SliceStable(placed, func(i, j int) bool {
+		if placed[i].Start.Y != placed[j].Start.Y {
+			return placed[i].Start.Y < placed[j].Start.Y
+		}
+		return placed[i].Start.X < placed[j].Start.X
+	})
+
+	clues := Clues{Across: []Clue{}, Down: []Clue{}}
+	numbers := make(map[Location]int)
+	for _, p := range placed {
+		number, ok := numbers[p.Start]
+		if !ok {
+			number = len(numbers) + 1
+			numbers[p.Start] = number
+		}
+		clue := Clue{Number: number, Start: p.Start, Direction: p.Direction, Word: p.Word, Hint: p.Hint}
+		if p.Direction == Across {
+			clues.Across = append(clues.Across, clue)
+		} else {
+			clues.Down = append(clues.Down, clue)
+		}
+	}
+	return clues
+}
+
+// NumberAt returns the number printed in the cell at loc, or zero if no
+// word starts there.
+func (c Clues) NumberAt(loc Location) int {
+	for _, list := range [][]Clue{c.Across, c.Down} {
+		for _, clue := range list {
+			if clue.Start == loc {
+				return clue.Number
+			}
+		}
+	}
+	return 0
+}
+
+// All returns the across and down clues merged in the order they are read
+// out: by number, across before down.
+func (c Clues) All() []Clue {
+	all := make([]Clue, 0, len(c.Across)+len(c.Down))
+	i, j := 0, 0
+	for i < len(c.Across) || j < len(c.Down) {
+		if j >= len(c.Down) || (i < len(c.Across) && c.Across[i].Number <= c.Down[j].Number) {
+			all = append(all, c.Across[i])
+			i++
+		} else {
+			all = append(all, c.Down[j])
+			j++
+		}
+	}
+	return all
+}
@@ -0,0 +1,49 @@
+package board_test
+
+import (
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+)
+
+func TestClues(t *testing.T) {
+	bounds, err := board.NewBoundsRectangle(5, 5)
+	if err != nil {
+		t.Fatalf("Failed to create bounds: %s", err)
+	}
+	b := board.NewBoard(bounds, 4, &board.OSFileWriter{})
+	// Placed out of reading order on purpose.
+	b.PlaceWordAt(board.Location{X: 0, Y: 3}, "eis", board.Across)
+	b.PlaceWordAt(board.Location{X: 3, Y: 0}, "see", board.Down)
+	b.PlaceEntryAt(board.Location{X: 0, Y: 0}, words.Entry{Word: "hase", Hint: "Langohr"}, board.Down)
+	b.PlaceEntryAt(board.Location{X: 0, Y: 0}, words.Entry{Word: "haus", Hint: "Gebäude"}, board.Across)
+	b.SaveBestSolution()
+
+	clues := b.Clues()
+
+	want := []board.Clue{
+		{Number: 1, Start: board.Location{X: 0, Y: 0}, Direction: board.Across, Word: "haus", Hint: "Gebäude"},
+		{Number: 1, Start: board.Location{X: 0, Y: 0}, Direction: board.Down, Word: "hase", Hint: "Langohr"},
+		{Number: 2, Start: board.Location{X: 3, Y: 0}, Direction: board.Down, Word: "see"},
+		{Number: 3, Start: board.Location{X: 0, Y: 3}, Direction: board.Across, Word: "eis"},
+	}
+	got := clues.All()
+	if len(got) != len(want) {
+		t.Fatalf("Incorrect number of clues, got: %d, want: %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Incorrect clue %d, got: %+v, want: %+v", i, got[i], want[i])
+		}
+	}
+	if len(clues.Across) != 2 || len(clues.Down) != 2 {
+		t.Errorf("Incorrect split, got: %d across and %d down, want: 2 and 2", len(clues.Across), len(clues.Down))
+	}
+
+	for loc, want := range map[board.Location]int{{X: 0, Y: 0}: 1, {X: 3, Y: 0}: 2, {X: 0, Y: 3}: 3, {X: 1, Y: 0}: 0} {
+		if got := clues.NumberAt(loc); got != want {
+			t.Errorf("Incorrect number at %v, got: %d, want: %d", loc, got, want)
+		}
+	}
+}
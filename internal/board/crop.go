@@ -0,0 +1,111 @@
+package board
+
+// Size describes the smallest rectangle around the letters of a best
+// solution.
+type Size struct {
+	Width, Height int
+	// Density is the share of the cells of the rectangle that hold a
+	// letter, between 0 and 1. Cells the mask blocks do not count.
+	Density float64
+}
+
+// Area returns the number of cells of the rectangle.
+func (s Size) Area() int {
+	return s.Width * s.Height
+}
+
+// BestSize returns the size of the smallest rectangle around the letters of
+// the best solution, and its top left corner. Without letters, the size is
+// zero.
+func (b *Board) BestSize() (Size, Location) {
+	minX, minY, maxX, maxY := -1, -1, -1, -1
+	for y, row := range b.BestBoard {
+		for x, cell := range row {
+			if cell == nil || !cell.Filled {
+				continue
+			}
+			if minX < 0 || x < minX {
+				minX = x
+			}
+			if minY < 0 {
+				minY = y
+			}
+			maxX, maxY = max(maxX, x), y
+		}
+	}
+	if minX < 0 {
+		return Size{}, Location{}
+	}
+
+	size := Size{Width: maxX - minX + 1, Height: maxY - minY + 1}
+	letters, cells := 0, 0
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			if !b.Allowed(x, y) {
+				continue
+			}
+			cells++
+			if b.BestBoard[y][x].Filled {
+				letters++
+			}
+		}
+	}
+	size.Density = float64(letters) / float64(cells)
+	return size, Location{X: minX, Y: minY}
+}
+
+// Crop cuts the board down to the smallest rectangle around the letters of
+// its best solution and returns its size. The cells, the mask and the
+// placed words of both the best solution and the current layout are moved
+// so the rectangle starts at 0, 0. It is meant for finished boards, whose
+// current layout is empty or the best solution; the letters of the current
+// layout outside the rectangle are lost. A board without a best solution is
+// left as it is.
+func (b *Board) Crop() Size {
+	size, from := b.BestSize()
+	if size.Area() == 0 {
+		return size
+	}
+
+	b.Cells = cropCells(b.Cells, from, size)
+	b.BestBoard = cropCells(b.BestBoard, from, size)
+	b.PlacedWords = movePlacedWords(b.PlacedWords, from)
+	b.BestPlacedWords = movePlacedWords(b.BestPlacedWords, from)
+	if b.Mask != nil {
+		mask, _ := NewMask(size.Width, size.Height)
+		for y := 0; y < size.Height; y++ {
+			for x := 0; x < size.Width; x++ {
+				if !b.Mask.Allowed(from.X+x, from.Y+y) {
+					mask.Block(x, y)
+				}
+			}
+		}
+		b.Mask = mask
+	}
+	b.Bounds, _ = NewBoundsRectangle(size.Width, size.Height)
+	return size
+}
+
+// cropCells returns the cells of the rectangle of the given size starting
+// at from.
+func cropCells(cells [][]*Cell, from Location, size Size) [][]*Cell {
+	cropped := make([][]*Cell, size.Height)
+	for y := range cropped {
+		cropped[y] = cells[from.Y+y][from.X : from.X+size.Width]
+	}
+	return cropped
+}
+
+// movePlacedWords returns the placed words with their starts moved so that
+// from becomes 0, 0.
+func movePlacedWords(placed []PlacedWord, from Location) []PlacedWord {
+	if placed == nil {
+		return nil
+	}
+	moved := make([]PlacedWord, len(placed))
+	for i, p := range placed {
+		p.Start = Location{X: p.Start.X - from.X, Y: p.Start.Y - from.Y}
+		moved[i] = p
+	}
+	return moved
+}
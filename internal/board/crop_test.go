@@ -0,0 +1,71 @@
+package board_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+)
+
+func TestBoard_Crop(t *testing.T) {
+	bounds, err := board.NewBoundsRectangle(8, 7)
+	if err != nil {
+		t.Fatalf("Failed to create bounds: %s", err)
+	}
+	b := board.NewBoard(bounds, 2, &board.OSFileWriter{})
+	mask, err := board.ParseMask(strings.NewReader("#.......\n........\n........\n........\n........\n...#....\n.......#\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := b.SetMask(mask); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	b.PlaceWordAt(board.Location{X: 2, Y: 3}, "haus", board.Across)
+	b.PlaceWordAt(board.Location{X: 5, Y: 3}, "see", board.Down)
+	b.SaveBestSolution()
+
+	// The letters fill 6 of the 11 cells the mask allows in a 4 by 3
+	// rectangle at 2, 3.
+	want := board.Size{Width: 4, Height: 3, Density: 6.0 / 11}
+	size, from := b.BestSize()
+	if size != want || from != (board.Location{X: 2, Y: 3}) {
+		t.Errorf("Incorrect best size, got: %+v at %v, want: %+v at (2, 3)", size, from, want)
+	}
+
+	if got := b.Crop(); got != want {
+		t.Errorf("Incorrect size of the cropped board, got: %+v, want: %+v", got, want)
+	}
+	if b.Bounds.Width() != 4 || b.Bounds.Height() != 3 || len(b.BestBoard) != 3 || len(b.BestBoard[0]) != 4 || len(b.Cells) != 3 || len(b.Cells[0]) != 4 {
+		t.Errorf("Incorrect board size, got: %dx%d", b.Bounds.Width(), b.Bounds.Height())
+	}
+	wantPlaced := []board.PlacedWord{
+		{Start: board.Location{X: 0, Y: 0}, Direction: board.Across, Word: "haus"},
+		{Start: board.Location{X: 3, Y: 0}, Direction: board.Down, Word: "see"},
+	}
+	if !reflect.DeepEqual(b.BestPlacedWords, wantPlaced) || !reflect.DeepEqual(b.PlacedWords, wantPlaced) {
+		t.Errorf("Incorrect placed words, got: %+v, want: %+v", b.BestPlacedWords, wantPlaced)
+	}
+	if got := b.BestBoard[2][3].Character; got != "e" {
+		t.Errorf("Incorrect last letter, got: %q, want: %q", got, "e")
+	}
+	if got := b.Mask.String(); got != "....\n....\n.#..\n" {
+		t.Errorf("Incorrect mask, got: %q", got)
+	}
+
+	// Cropping again changes nothing.
+	if got := b.Crop(); got != want || b.Bounds.Width() != 4 {
+		t.Errorf("Incorrect size of the board cropped twice, got: %+v", got)
+	}
+}
+
+func TestBoard_CropEmpty(t *testing.T) {
+	bounds, err := board.NewBoundsRectangle(5, 5)
+	if err != nil {
+		t.Fatalf("Failed to create bounds: %s", err)
+	}
+	b := board.NewBoard(bounds, 1, &board.OSFileWriter{})
+	if got := b.Crop(); got != (board.Size{}) || b.Bounds.Width() != 5 {
+		t.Errorf("Expected an empty board to stay as it is, got: %+v", got)
+	}
+}
@@ -1,5 +1,10 @@
 package board
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Direction indicates the direction in which a word is placed on the
 // crossword board. It is used to specify whether a word runs
 // horizontally or vertically.
@@ -11,3 +16,14 @@ const (
 	// Down (1) indicates a vertical placement, from top to bottom.
 	Down
 )
+
+// ParseDirection parses "across" or "down", in any case.
+func ParseDirection(s string) (Direction, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "across":
+		return Across, nil
+	case "down":
+		return Down, nil
+	}
+	return Across, fmt.Errorf("unknown direction %q, use across or down", s)
+}
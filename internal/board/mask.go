@@ -0,0 +1,107 @@
+package board
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Mask marks the cells of a board that may hold letters, so a board can take
+// a shape like a heart or a tree instead of filling its whole rectangle.
+type Mask struct {
+	width, height int
+	blocked       []bool // Whether a cell may not hold a letter, row by row.
+}
+
+// NewMask returns a mask of the given size with all cells allowed.
+func NewMask(width, height int) (*Mask, error) {
+	if width < 1 || height < 1 {
+		return nil, fmt.Errorf("width and height must be positive numbers")
+	}
+	return &Mask{width: width, height: height, blocked: make([]bool, width*height)}, nil
+}
+
+// ParseMask reads a mask drawn as text, one line per row. A '.' is a cell
+// that may hold a letter; a '#', a space or any other character is a
+// blocked cell, as are the cells missing at the end of lines shorter than
+// the longest one. Blank lines at the start and end are ignored.
+func ParseMask(r io.Reader) (*Mask, error) {
+	var lines [][]rune
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, []rune(strings.TrimRight(scanner.Text(), "\r")))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the mask: %w", err)
+	}
+	for len(lines) > 0 && strings.TrimSpace(string(lines[0])) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(string(lines[len(lines)-1])) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	width := 0
+	for _, line := range lines {
+		width = max(width, len(line))
+	}
+	if width == 0 {
+		return nil, errors.New("empty mask")
+	}
+	m, err := NewMask(width, len(lines))
+	if err != nil {
+		return nil, err
+	}
+	allowed := 0
+	for y, line := range lines {
+		for x := 0; x < width; x++ {
+			if x < len(line) && line[x] == '.' {
+				allowed++
+				continue
+			}
+			m.Block(x, y)
+		}
+	}
+	if allowed == 0 {
+		return nil, errors.New("mask without cells for letters, use '.' for them")
+	}
+	return m, nil
+}
+
+// Width returns the number of columns of the mask.
+func (m *Mask) Width() int {
+	return m.width
+}
+
+// Height returns the number of rows of the mask.
+func (m *Mask) Height() int {
+	return m.height
+}
+
+// Allowed reports whether the cell at x, y may hold a letter.
+func (m *Mask) Allowed(x, y int) bool {
+	return !m.blocked[y*m.width+x]
+}
+
+// Block marks the cell at x, y as one that may not hold a letter.
+func (m *Mask) Block(x, y int) {
+	m.blocked[y*m.width+x] = true
+}
+
+// String draws the mask like ParseMask reads it.
+func (m *Mask) String() string {
+	var sb strings.Builder
+	for y := 0; y < m.height; y++ {
+		for x := 0; x < m.width; x++ {
+			if m.Allowed(x, y) {
+				sb.WriteByte('.')
+			} else {
+				sb.WriteByte('#')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
@@ -0,0 +1,106 @@
+package board_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+)
+
+func TestParseMask(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "rectangle",
+			in:   "...\n...\n",
+			want: "...\n...\n",
+		},
+		{
+			name: "ragged lines and blank lines",
+			in:   "\n .. \n....\n.\n\n",
+			want: "#..#\n....\n.###\n",
+		},
+		{
+			name: "windows line endings",
+			in:   ".#.\r\n...\r\n",
+			want: ".#.\n...\n",
+		},
+		{
+			name:    "empty",
+			in:      "\n\n",
+			wantErr: true,
+		},
+		{
+			name:    "no cells for letters",
+			in:      "###\n# #\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := board.ParseMask(strings.NewReader(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Incorrect error, got: %v, want error: %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := m.String(); got != tt.want {
+				t.Errorf("Incorrect mask, got: %q, want: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoard_Mask(t *testing.T) {
+	m, err := board.ParseMask(strings.NewReader(".....\n.#...\n.....\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	bounds, err := board.NewBoundsRectangle(5, 3)
+	if err != nil {
+		t.Fatalf("Failed to create bounds: %s", err)
+	}
+	b := board.NewBoard(bounds, 2, &board.OSFileWriter{})
+	if err := b.SetMask(m); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	b.PlaceWordAt(board.Location{X: 0, Y: 0}, "haus", board.Across)
+
+	tests := []struct {
+		name  string
+		start board.Location
+		word  string
+		dir   board.Direction
+		want  bool
+	}{
+		{"down through a blocked cell", board.Location{X: 1, Y: 0}, "abc", board.Down, false},
+		{"down through allowed cells", board.Location{X: 2, Y: 0}, "use", board.Down, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.CanPlaceWordAt(tt.start, tt.word, tt.dir); got != tt.want {
+				t.Errorf("Incorrect CanPlaceWordAt, got: %v, want: %v", got, tt.want)
+			}
+			if got := b.Fits(tt.start, tt.word, tt.dir); got != tt.want {
+				t.Errorf("Incorrect Fits, got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+
+	if clone := b.Clone(); clone.Mask != m {
+		t.Errorf("Expected the clone to share the mask")
+	}
+
+	other, err := board.NewMask(3, 5)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := b.SetMask(other); err == nil {
+		t.Errorf("Expected an error for a mask of another size")
+	}
+}
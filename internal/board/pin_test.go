@@ -0,0 +1,61 @@
+package board_test
+
+import (
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+)
+
+func TestBoard_CanPinWordAt(t *testing.T) {
+	bounds, err := board.NewBoundsRectangle(7, 7)
+	if err != nil {
+		t.Fatalf("Failed to create bounds: %s", err)
+	}
+	b := board.NewBoard(bounds, 2, &board.OSFileWriter{})
+	b.PlaceWordAt(board.Location{X: 1, Y: 3}, "haus", board.Across)
+
+	tests := []struct {
+		name      string
+		start     board.Location
+		word      string
+		direction board.Direction
+		want      bool
+	}{
+		{"apart", board.Location{X: 0, Y: 0}, "see", board.Across, true},
+		{"crossing", board.Location{X: 4, Y: 3}, "see", board.Down, true},
+		{"off the board", board.Location{X: 5, Y: 0}, "see", board.Across, false},
+		{"wrong letter", board.Location{X: 2, Y: 1}, "see", board.Down, false},
+		{"parallel", board.Location{X: 1, Y: 4}, "see", board.Across, false},
+		{"touching the end", board.Location{X: 5, Y: 3}, "see", board.Across, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.CanPinWordAt(tt.start, tt.word, tt.direction); got != tt.want {
+				t.Errorf("Incorrect result, got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDirection(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    board.Direction
+		wantErr bool
+	}{
+		{in: "across", want: board.Across},
+		{in: " Down ", want: board.Down},
+		{in: "diagonal", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := board.ParseDirection(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Incorrect error, got: %v, want error: %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Incorrect direction, got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
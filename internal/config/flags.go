@@ -1 +1,111 @@
 package config
+
+import (
+	"flag"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/Germanicus1/crizzcrozz/internal/generators"
+)
+
+// Options holds the settings of a crossword run as given on the command
+// line.
+type Options struct {
+	FileName        string  // The CSV file with the words and hints.
+	Dictionary      string  // The CSV file with the words dense grids are filled with, if set.
+	Width           int     // The width of the board.
+	Height          int     // The height of the board; the width if zero.
+	Mask            string  // The file with the shape of the board, if set.
+	Pinned          string  // The CSV file with the words placed by hand, if set.
+	Crop            bool    // Cut the board down to the rectangle around its letters.
+	MaxRetries      int     // The max number of attempts to build the crossword.
+	FindOptimalSize bool    // Search for the smallest board that fits all words.
+	Aspect          float64 // The ratio of width to height of the boards searched; any if zero.
+	Estimate        bool    // Estimate the board size from the words.
+	Generator       string  // The name of the generator.
+	Symmetry        string  // The symmetry of free-form layouts.
+	Objective       string  // What makes a board good, a preset or weights like "words=1,interlock=0.2"; the default if empty.
+	Seed            int64   // The seed of the random choices; random if zero.
+	Workers         int     // The number of searches run in parallel.
+	Debug           bool    // Trace every placement of the searches.
+
+	Timeout       time.Duration // Stop generating after this time; no limit if zero.
+	MaxBacktracks int           // The max number of backtracks per attempt; no limit if zero.
+	MaxNodes      int           // The max number of word placements per attempt; no limit if zero.
+
+	JSONFileName string // Save the puzzle as JSON to this file, if set.
+	PuzFileName  string // Save the puzzle as Across Lite .puz to this file, if set.
+	IPuzFileName string // Save the puzzle as ipuz to this file, if set.
+	SVGFileName  string // Draw the grid as SVG to this file, if set.
+	PDFFileName  string // Print a worksheet as PDF to this file, if set.
+	HTMLFileName string // Save an interactive HTML player to this file, if set.
+	Paper        string // The paper size of PDF worksheets.
+	Answers      bool   // Include the solution in printable output.
+	Title        string // The title stored in exported puzzles.
+	Author       string // The author stored in exported puzzles.
+	Copyright    string // The copyright notice stored in exported puzzles.
+}
+
+// ParseFlags parses the command line arguments (without the program name)
+// into Options.
+func ParseFlags(args []string) (*Options, error) {
+	opts := &Options{}
+	fs := flag.NewFlagSet("crossword", flag.ContinueOnError)
+	fs.StringVar(&opts.FileName, "f", "vocabulary.csv", "Specify the file with the words and hints. Defaults to vocabulary.csv.")
+	fs.StringVar(&opts.Dictionary, "d", "", "Specify a file with more words and hints to fill dense and american grids with. Only the words of -f are used if empty.")
+	fs.IntVar(&opts.Width, "w", 1, "Specify the width of the board. Defaults to 1.")
+	fs.IntVar(&opts.Height, "h", 0, "Specify the height of the board. Defaults to the width.")
+	fs.StringVar(&opts.Mask, "mask", "", "Specify a file with the shape of the board, one line per row with '.' for cells that may hold letters and '#' for cells that may not. The shape sets the size of the board. Defaults to a full rectangle.")
+	fs.StringVar(&opts.Pinned, "pin", "", "Specify a CSV file with words to place by hand before the others, with the columns word, hint, x, y and direction (across or down). x and y count from 0 at the top left. Only the asymmetrical generator places them. No words are pinned if empty.")
+	fs.BoolVar(&opts.Crop, "crop", true, "Decide if the board is cut down to the smallest rectangle around its letters once it is built. Default TRUE.")
+	fs.IntVar(&opts.MaxRetries, "r", 1, "Specify the max number of retries to build the crossword. Defaults to 1.")
+	fs.BoolVar(&opts.FindOptimalSize, "o", false, "Decide if the generator searches for the smallest board that fits all words, instead of using -w and -h. The search stops after -timeout, or after a minute without one. Default FALSE.")
+	fs.Float64Var(&opts.Aspect, "aspect", 0, "Specify the ratio of width to height of the boards -o tries, e.g. 1 for square boards or 1.5 for boards half again as wide as high. Defaults to any ratio up to 2, with the board cropped to its letters.")
+	fs.BoolVar(&opts.Estimate, "e", true, "Decide if the program can calculate an estimated board size. Default FALSE.")
+	fs.StringVar(&opts.Generator, "g", generators.Asymmetrical, "Specify the generator, one of: "+strings.Join(generators.Names, ", ")+". Defaults to "+generators.Asymmetrical+".")
+	fs.StringVar(&opts.Symmetry, "symmetry", string(generators.NoSymmetry), "Specify the symmetry the asymmetrical generator aims for, one of: "+strings.Join(generators.SymmetryNames, ", ")+". Defaults to "+string(generators.NoSymmetry)+".")
+	fs.StringVar(&opts.Objective, "objective", "", "Specify what makes a board good, which the best of the -r attempts and of the boards of each generator is kept by, as one of: "+strings.Join(generators.ObjectiveNames, ", ")+", or as weights of words, intersections, compactness, interlock, checked, density, aspect and spread, e.g. compact,spread=0.1 or words=1,interlock=0.5. Defaults to words=1,intersections=0.01,compactness=0.1.")
+	fs.Int64Var(&opts.Seed, "seed", 0, "Specify the seed of the random choices to regenerate a puzzle. Defaults to a random seed.")
+	fs.IntVar(&opts.Workers, "workers", runtime.NumCPU(), "Specify the number of searches run in parallel. Defaults to the number of CPUs.")
+	fs.BoolVar(&opts.Debug, "debug", false, "Decide if every placement, removal and check of the search is printed. Default FALSE.")
+	fs.DurationVar(&opts.Timeout, "timeout", 0, "Specify the max time to search for a crossword, e.g. 30s. The best board found so far is shown when it runs out. Defaults to no limit.")
+	fs.IntVar(&opts.MaxBacktracks, "backtracks", generators.DefaultMaxBacktracks, "Specify the max number of backtracks per attempt, 0 for no limit. Defaults to 50000.")
+	fs.IntVar(&opts.MaxNodes, "nodes", 0, "Specify the max number of word placements tried per attempt. Defaults to no limit.")
+	fs.StringVar(&opts.JSONFileName, "j", "", "Specify a file to save the generated puzzle to as JSON. Nothing is saved if empty.")
+	fs.StringVar(&opts.PuzFileName, "p", "", "Specify a file to save the generated puzzle to in the Across Lite .puz format. Nothing is saved if empty.")
+	fs.StringVar(&opts.IPuzFileName, "i", "", "Specify a file to save the generated puzzle to in the ipuz format. Nothing is saved if empty.")
+	fs.StringVar(&opts.SVGFileName, "svg", "", "Specify a file to draw the puzzle grid to as SVG. Nothing is drawn if empty.")
+	fs.StringVar(&opts.PDFFileName, "pdf", "", "Specify a file to print a worksheet with grid and clues to as PDF. Nothing is printed if empty.")
+	fs.StringVar(&opts.Paper, "paper", "A4", "Specify the paper size of PDF worksheets, A4 or Letter. Defaults to A4.")
+	fs.StringVar(&opts.HTMLFileName, "html", "", "Specify a file to save a self-contained HTML page for solving the puzzle to. Nothing is saved if empty.")
+	fs.BoolVar(&opts.Answers, "answers", false, "Decide if printable output includes the solution. Default FALSE.")
+	fs.StringVar(&opts.Title, "title", "", "Specify the title stored in exported puzzles.")
+	fs.StringVar(&opts.Author, "author", "", "Specify the author stored in exported puzzles.")
+	fs.StringVar(&opts.Copyright, "copyright", "", "Specify the copyright notice stored in exported puzzles.")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// ServeOptions holds the settings of the HTTP API server.
+type ServeOptions struct {
+	Addr          string        // The address to listen on.
+	Timeout       time.Duration // The max time a request waits for its puzzle.
+	MaxConcurrent int           // The max number of puzzles generated at a time.
+}
+
+// ParseServeFlags parses the arguments of the serve command (without the
+// command name) into ServeOptions.
+func ParseServeFlags(args []string) (*ServeOptions, error) {
+	opts := &ServeOptions{}
+	fs := flag.NewFlagSet("crossword serve", flag.ContinueOnError)
+	fs.StringVar(&opts.Addr, "addr", "localhost:8080", "Specify the address the API listens on. Defaults to localhost:8080.")
+	fs.DurationVar(&opts.Timeout, "timeout", time.Minute, "Specify the max time a request may take. Defaults to 1m.")
+	fs.IntVar(&opts.MaxConcurrent, "max-concurrent", runtime.NumCPU(), "Specify the max number of puzzles generated at a time. Defaults to the number of CPUs.")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
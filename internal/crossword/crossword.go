@@ -0,0 +1,324 @@
+// Package crossword builds crossword boards from word lists. It ties the
+// word pool, the board and the generators together so the command line
+// tool and the HTTP server share one pipeline.
+package crossword
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/generators"
+	"github.com/Germanicus1/crizzcrozz/internal/parse"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+	"github.com/Germanicus1/crizzcrozz/pkg/models"
+)
+
+// ErrNoWordsPlaced is returned when not a single word fits on the board.
+var ErrNoWordsPlaced = errors.New("no words could be placed")
+
+// Options controls how a crossword is built.
+type Options struct {
+	Width  int // The width of the board; estimated from the words if zero.
+	Height int // The height of the board; the width if zero.
+	// MaxRetries is the number of attempts to build the crossword; at
+	// least one is made. The board of the highest score by the objective
+	// among those with the most words is kept.
+	MaxRetries int
+	Generator  string // The name of the generator; the default generator if empty.
+	Seed       int64  // The seed of the random choices; a random seed is picked if zero.
+	Workers    int    // The number of searches run in parallel; one if zero.
+	// Mask gives the board its shape and size, which then override Width
+	// and Height; letters only go in the cells it allows. A full rectangle
+	// if nil.
+	Mask *board.Mask
+	// Dictionary holds the words the dense generators fill the grid with,
+	// besides the words to build the crossword from.
+	Dictionary []*models.WordsAndHints
+	// Symmetry is the layout the free-form generator aims for; none if
+	// empty. Only the default generator follows a symmetry.
+	Symmetry generators.Symmetry
+	// Objective scores the boards, to keep the best of all attempts and of
+	// the boards each generator finds; the default one if zero. The
+	// annealing and genetic generators optimize it.
+	Objective generators.Objective
+	// Pinned holds words placed by hand, at fixed positions and directions,
+	// that the other words are placed around. Pinned words missing from the
+	// words to build the crossword from are added to them. Only the default
+	// generator places pinned words.
+	Pinned []*models.PinnedWord
+	// Crop cuts the board down to the smallest rectangle around its letters
+	// once it is built.
+	Crop bool
+
+	Budget generators.Budget // Limits the work of each attempt.
+}
+
+// Result is the outcome of Build.
+type Result struct {
+	Board      *board.Board      // The board of the best attempt, its best solution holds the placed words.
+	Reason     generators.Reason // Why the search stopped short of all words; Complete if all words were placed.
+	Nodes      int               // The number of word placements tried in all attempts.
+	Backtracks int               // The number of placements taken back in all attempts.
+	// Symmetry is the share of letters of the board whose mirror cell holds
+	// a letter too, 1 if the board follows the symmetry of the options or
+	// there was none to follow.
+	Symmetry float64
+	// Size is the size of the smallest rectangle around the letters of the
+	// board, and how densely they fill it. It is the size of the board if
+	// the options crop it.
+	Size board.Size
+	// Score is the score of the board by the objective of the options, term
+	// by term.
+	Score generators.Breakdown
+}
+
+// Build generates a crossword from the words and returns the board of the
+// best attempt, the one with the most words and then the highest score. If
+// not all words could be placed, the best solution of the board holds as
+// many words as were placed in the best attempt, and the reason tells why
+// the search stopped. Build stops early when ctx is done. The seed used is
+// recorded in the board, so building the same words with the same options
+// and seed gives the same board again. With more than one worker, the seed
+// of the search that found the board is recorded; building again with that
+// seed and one worker gives the same board.
+func Build(ctx context.Context, wordsAndHints []*models.WordsAndHints, opts Options) (*Result, error) {
+	if len(wordsAndHints) == 0 && len(opts.Pinned) == 0 {
+		return nil, errors.New("no words given")
+	}
+	pinned, err := parse.CleanPinnedWords(opts.Pinned)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", generators.ErrInvalidPin, err)
+	}
+	return build(ctx, wordsAndHints, opts, pinned)
+}
+
+// build is Build with the pinned words of the options cleaned.
+func build(ctx context.Context, wordsAndHints []*models.WordsAndHints, opts Options, pinned []board.PlacedWord) (*Result, error) {
+	var err error
+	sortedWords := words.SortByLength(addPinnedWords(parse.CleanWords(wordsAndHints), pinned))
+	dictionary := parse.CleanWords(opts.Dictionary)
+
+	width, height := opts.Width, opts.Height
+	if width == 0 {
+		width = EstimateInitialBoardSize(sortedWords)
+		// Make room for the pinned words, if the estimate did not.
+		for _, p := range pinned {
+			width = max(width, wordEnd(p).X+1)
+			if opts.Height == 0 {
+				width = max(width, wordEnd(p).Y+1)
+			}
+		}
+	}
+	if height == 0 {
+		height = width
+	}
+	if opts.Mask != nil {
+		width, height = opts.Mask.Width(), opts.Mask.Height()
+	}
+	maxRetries := opts.MaxRetries
+	if maxRetries < 1 {
+		maxRetries = 1
+	}
+	objective := opts.Objective
+	if objective == (generators.Objective{}) {
+		objective = generators.DefaultObjective
+	}
+
+	seed := opts.Seed
+	for seed == 0 {
+		seed = rand.Int63()
+	}
+	// The searches of a portfolio already keep the CPUs busy, so the
+	// generators run on one goroutine each.
+	genWorkers := 0
+	if opts.Workers > 1 {
+		genWorkers = 1
+	}
+	search := func(ctx context.Context, seed int64) (*Result, error) {
+		genOpts := generators.Options{Rand: rand.New(rand.NewSource(seed)), Budget: opts.Budget, Dictionary: dictionary, Symmetry: opts.Symmetry, Objective: objective, Pinned: pinned, Workers: genWorkers}
+		res, err := createBoard(ctx, sortedWords, maxRetries, width, height, opts.Mask, opts.Generator, genOpts)
+		if err != nil {
+			return nil, err
+		}
+		if res.Board != nil {
+			res.Board.Seed = seed
+		}
+		return res, nil
+	}
+
+	var res *Result
+	if opts.Workers > 1 {
+		res, err = portfolio(ctx, opts.Workers, seed, objective, search)
+	} else {
+		res, err = search(ctx, seed)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if res.Board == nil || res.Board.BestWordCount == 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, ErrNoWordsPlaced
+	}
+	res.Symmetry = generators.SymmetryScore(res.Board, opts.Symmetry)
+	res.Score = objective.Breakdown(res.Board.BestBoard)
+	if opts.Crop {
+		res.Size = res.Board.Crop()
+	} else {
+		res.Size, _ = res.Board.BestSize()
+	}
+	return res, nil
+}
+
+// addPinnedWords adds the pinned words missing from entries to them. A
+// word pinned twice has to be in entries twice.
+func addPinnedWords(entries []words.Entry, pinned []board.PlacedWord) []words.Entry {
+	count := make(map[string]int, len(entries))
+	for _, e := range entries {
+		count[e.Word]++
+	}
+	for _, p := range pinned {
+		if count[p.Word] > 0 {
+			count[p.Word]--
+			continue
+		}
+		entries = append(entries, words.Entry{Word: p.Word, Hint: p.Hint})
+	}
+	return entries
+}
+
+// wordEnd returns the location of the last letter of the placed word.
+func wordEnd(p board.PlacedWord) board.Location {
+	n := len([]rune(p.Word)) - 1
+	if p.Direction == board.Across {
+		return board.Location{X: p.Start.X + n, Y: p.Start.Y}
+	}
+	return board.Location{X: p.Start.X, Y: p.Start.Y + n}
+}
+
+// setUpBoard initializes a crossword board with given dimensions and a
+// list of words. It returns a pointer to the created board or an error
+// if the board cannot be created.
+func setUpBoard(width, height int, wordCount int) (*board.Board, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid board dimensions (width: %d, height: %d)", width, height)
+	}
+
+	bounds, err := board.NewBoundsRectangle(width, height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create board boundaries: %w", err)
+	}
+
+	fileWriter := &board.OSFileWriter{} // Creating an instance of FileWriter
+	b := board.NewBoard(bounds, wordCount, fileWriter)
+	if b == nil {
+		return nil, fmt.Errorf("failed to initialize the crossword board")
+	}
+	return b, nil
+}
+
+// generateCrossword makes one attempt to populate the crossword board with
+// words. It returns an error only if the generator cannot run.
+func generateCrossword(ctx context.Context, b *board.Board, entries []words.Entry, generatorName string, opts generators.Options) (generators.Result, error) {
+	newPool := words.NewPool()
+	newPool.LoadEntries(entries)
+
+	generator, err := generators.New(generatorName, b, newPool, opts)
+	if err != nil {
+		return generators.Result{}, err
+	}
+	return generator.Generate(ctx)
+}
+
+// EstimateInitialBoardSize guesses the width of a square board that fits
+// the words.
+func EstimateInitialBoardSize(words []words.Entry) int {
+	wordCount := len(words)
+	if wordCount == 0 {
+		return 10 // Default minimum size
+		// FIXME: This should probably retrun an error.
+	}
+
+	longestWord := 0
+	totalLength := 0
+
+	for _, word := range words {
+		wordLen := word.Length()
+		totalLength += wordLen
+		if wordLen > longestWord {
+			longestWord = wordLen
+		}
+	}
+
+	averageWordLength := totalLength / wordCount
+
+	// Adjust density factor based on expected intersection
+	densityFactor := 1.2 // Increase density factor for better spacing
+
+	// Adjust padding dynamically based on longest word and total words
+	padding := int(math.Max(float64(longestWord)*0.3, float64(wordCount)*0.3)) // More words → more padding
+
+	// Estimate board size using an improved formula
+	estimatedSize := int(math.Sqrt(float64(wordCount) * float64(averageWordLength) * densityFactor))
+
+	// Ensure it's at least large enough for the longest word + padding
+	if estimatedSize < longestWord+padding {
+		estimatedSize = longestWord + padding
+	}
+
+	// Add extra space for flexibility
+	// estimatedSize += 3
+
+	return estimatedSize
+}
+
+// createBoard makes maxRetries attempts to build the crossword on a fresh
+// board each, and returns the best board: the one with the most words and
+// then the highest score by the objective of the options. It stops early
+// when ctx is done or a budget runs out.
+func createBoard(ctx context.Context, sortedWords []words.Entry, maxRetries, width, height int, mask *board.Mask, generatorName string, opts generators.Options) (*Result, error) {
+	// Track the best attempt
+	best := &Result{}
+	emptyBoard, err := setUpBoard(width, height, len(sortedWords))
+	if err != nil {
+		return nil, err
+	}
+	if err := emptyBoard.SetMask(mask); err != nil {
+		return nil, err
+	}
+	for attempt := 0; attempt < maxRetries; attempt++ { // Limit attempts to prevent infinite loops
+		tempBoard := emptyBoard.Clone() // Create a fresh board
+		res, err := generateCrossword(ctx, tempBoard, sortedWords, generatorName, opts)
+		if err != nil {
+			return nil, err
+		}
+		best.Nodes += res.Nodes
+		best.Backtracks += res.Backtracks
+
+		if best.Board == nil || isBetterBoard(tempBoard, best.Board, opts.Objective) {
+			best.Board, best.Reason = tempBoard, res.Reason
+		}
+		if res.Reason.Stopped() {
+			if best.Reason != generators.Complete {
+				best.Reason = res.Reason
+			}
+			break
+		}
+	}
+
+	return best, nil
+}
+
+// isBetterBoard reports whether the best solution of a holds more words than
+// that of b, or as many with a higher score by the objective.
+func isBetterBoard(a, b *board.Board, o generators.Objective) bool {
+	if a.BestWordCount != b.BestWordCount {
+		return a.BestWordCount > b.BestWordCount
+	}
+	return a.BestWordCount > 0 && o.Score(a.BestBoard) > o.Score(b.BestBoard)
+}
@@ -0,0 +1,250 @@
+package crossword_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/crossword"
+	"github.com/Germanicus1/crizzcrozz/internal/generators"
+	"github.com/Germanicus1/crizzcrozz/pkg/models"
+)
+
+func TestBuild_DuplicateAnswers(t *testing.T) {
+	wordsAndHints := []*models.WordsAndHints{
+		{Word: " schloss ", Hint: " Gebäude für Könige "},
+		{Word: "schloss", Hint: "Verschluss einer Tür"},
+		{Word: "hose", Hint: "Kleidungsstück"},
+	}
+	res, err := crossword.Build(context.Background(), wordsAndHints, crossword.Options{Width: 9, MaxRetries: 3})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	b := res.Board
+	if b.BestWordCount != 3 {
+		t.Fatalf("Incorrect number of placed words, got: %d, want: 3", b.BestWordCount)
+	}
+
+	hints := make(map[string]bool)
+	for _, clue := range b.Clues().All() {
+		hints[clue.Word+": "+clue.Hint] = true
+	}
+	for _, want := range []string{"schloss: Gebäude für Könige", "schloss: Verschluss einer Tür", "hose: Kleidungsstück"} {
+		if !hints[want] {
+			t.Errorf("Missing clue %q, got: %v", want, hints)
+		}
+	}
+}
+
+func TestBuild_Seed(t *testing.T) {
+	wordsAndHints := []*models.WordsAndHints{
+		{Word: "haus"}, {Word: "hase"}, {Word: "see"}, {Word: "eis"}, {Word: "ast"}, {Word: "tee"},
+	}
+	build := func(seed int64) []board.PlacedWord {
+		t.Helper()
+		res, err := crossword.Build(context.Background(), wordsAndHints, crossword.Options{Width: 8, MaxRetries: 2, Seed: seed})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		b := res.Board
+		if seed != 0 && b.Seed != seed {
+			t.Errorf("Incorrect seed recorded, got: %d, want: %d", b.Seed, seed)
+		}
+		if seed == 0 && b.Seed == 0 {
+			t.Errorf("Expected a random seed to be recorded")
+		}
+		return b.BestPlacedWords
+	}
+
+	first := build(7)
+	if again := build(7); !reflect.DeepEqual(first, again) {
+		t.Errorf("Same seed gave different boards, got: %v, want: %v", again, first)
+	}
+	build(0)
+}
+
+// TestBuild_Retries checks that each retry makes a single attempt, so the
+// work grows with the number of retries, not with its square.
+func TestBuild_Retries(t *testing.T) {
+	// Only haus can be placed, so every attempt tries the same placements.
+	wordsAndHints := []*models.WordsAndHints{{Word: "haus"}, {Word: "xyz"}, {Word: "qqq"}}
+	build := func(retries int) *crossword.Result {
+		t.Helper()
+		res, err := crossword.Build(context.Background(), wordsAndHints, crossword.Options{Width: 7, MaxRetries: retries, Seed: 1})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if res.Reason != generators.Exhausted {
+			t.Fatalf("Incorrect reason, got: %s, want: %s", res.Reason, generators.Exhausted)
+		}
+		return res
+	}
+
+	once := build(1)
+	if once.Nodes == 0 {
+		t.Fatalf("Expected an attempt to try placements")
+	}
+	const retries = 4
+	if got := build(retries); got.Nodes != retries*once.Nodes {
+		t.Errorf("Incorrect placements tried, got: %d, want: %d", got.Nodes, retries*once.Nodes)
+	}
+}
+
+func TestBuild_Workers(t *testing.T) {
+	wordsAndHints := []*models.WordsAndHints{
+		{Word: "haus"}, {Word: "hase"}, {Word: "see"}, {Word: "eis"}, {Word: "ast"}, {Word: "tee"},
+	}
+	res, err := crossword.Build(context.Background(), wordsAndHints, crossword.Options{Width: 8, MaxRetries: 2, Seed: 3, Workers: 4})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if res.Board.BestWordCount == 0 {
+		t.Fatalf("Expected placed words")
+	}
+
+	// The recorded seed rebuilds the board with a single worker.
+	again, err := crossword.Build(context.Background(), wordsAndHints, crossword.Options{Width: 8, MaxRetries: 2, Seed: res.Board.Seed})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(again.Board.BestPlacedWords, res.Board.BestPlacedWords) {
+		t.Errorf("Recorded seed gave a different board, got: %v, want: %v", again.Board.BestPlacedWords, res.Board.BestPlacedWords)
+	}
+}
+
+func TestBuild_Shape(t *testing.T) {
+	wordsAndHints := []*models.WordsAndHints{
+		{Word: "haus"}, {Word: "hase"}, {Word: "see"}, {Word: "eis"}, {Word: "ast"}, {Word: "tee"},
+	}
+	heart, err := board.ParseMask(strings.NewReader(" ... ... \n.........\n.........\n ....... \n  .....  \n   ...   \n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	tests := []struct {
+		name                  string
+		opts                  crossword.Options
+		wantWidth, wantHeight int
+	}{
+		{"square", crossword.Options{Width: 8}, 8, 8},
+		{"rectangle", crossword.Options{Width: 12, Height: 6}, 12, 6},
+		{"mask", crossword.Options{Width: 20, Height: 20, Mask: heart}, 9, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.MaxRetries, tt.opts.Seed = 3, 1
+			res, err := crossword.Build(context.Background(), wordsAndHints, tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			b := res.Board
+			if b.Bounds.Width() != tt.wantWidth || b.Bounds.Height() != tt.wantHeight {
+				t.Errorf("Incorrect board size, got: %dx%d, want: %dx%d", b.Bounds.Width(), b.Bounds.Height(), tt.wantWidth, tt.wantHeight)
+			}
+			for y, row := range b.BestBoard {
+				for x, cell := range row {
+					if cell.Filled && !b.Allowed(x, y) {
+						t.Errorf("Expected no letter at (%d, %d) outside the mask, got: %s", x, y, cell.Character)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBuild_Pinned(t *testing.T) {
+	wordsAndHints := []*models.WordsAndHints{{Word: "see"}, {Word: "eis"}, {Word: "ast"}}
+	pinned := []*models.PinnedWord{
+		{Word: "haus", Hint: "Gebäude", X: 0, Y: 2, Direction: "across"},
+		{Word: " see ", X: 3, Y: 2, Direction: "down"},
+	}
+	res, err := crossword.Build(context.Background(), wordsAndHints, crossword.Options{Width: 8, MaxRetries: 3, Seed: 1, Pinned: pinned})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	b := res.Board
+	if b.TotalWords != 4 || res.Reason != generators.Complete {
+		t.Fatalf("Incorrect result, got: %d/%d words %s, want: 4/4 words %s", b.BestWordCount, b.TotalWords, res.Reason, generators.Complete)
+	}
+	want := []board.PlacedWord{
+		{Start: board.Location{X: 0, Y: 2}, Direction: board.Across, Word: "haus", Hint: "Gebäude"},
+		{Start: board.Location{X: 3, Y: 2}, Direction: board.Down, Word: "see"},
+	}
+	if got := b.BestPlacedWords[:2]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Incorrect pinned words, got: %+v, want: %+v", got, want)
+	}
+
+	for _, tt := range []struct {
+		name string
+		pin  *models.PinnedWord
+	}{
+		{"unknown direction", &models.PinnedWord{Word: "haus", Direction: "up"}},
+		{"off the board", &models.PinnedWord{Word: "haus", X: 6, Direction: "across"}},
+		{"negative position", &models.PinnedWord{Word: "haus", X: -1, Direction: "across"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := crossword.Build(context.Background(), wordsAndHints, crossword.Options{Width: 8, Pinned: []*models.PinnedWord{tt.pin}})
+			if !errors.Is(err, generators.ErrInvalidPin) {
+				t.Errorf("Incorrect error, got: %v, want: %v", err, generators.ErrInvalidPin)
+			}
+		})
+	}
+}
+
+func TestBuild_Crop(t *testing.T) {
+	wordsAndHints := []*models.WordsAndHints{{Word: "haus"}, {Word: "see"}, {Word: "eis"}}
+	for _, crop := range []bool{false, true} {
+		res, err := crossword.Build(context.Background(), wordsAndHints, crossword.Options{Width: 15, Seed: 1, Crop: crop})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		b := res.Board
+		size, _ := b.BestSize()
+		if res.Size != size || size.Area() == 0 || size.Area() >= 15*15 {
+			t.Errorf("Incorrect size, got: %+v, want: %+v", res.Size, size)
+		}
+		wantWidth := 15
+		if crop {
+			wantWidth = size.Width
+		}
+		if b.Bounds.Width() != wantWidth || len(b.BestBoard[0]) != wantWidth {
+			t.Errorf("Incorrect board width with crop %v, got: %d, want: %d", crop, b.Bounds.Width(), wantWidth)
+		}
+		if b.BestWordCount != 3 {
+			t.Errorf("Incorrect number of words with crop %v, got: %d, want: 3", crop, b.BestWordCount)
+		}
+	}
+}
+
+func TestBuild_Objective(t *testing.T) {
+	wordsAndHints := []*models.WordsAndHints{
+		{Word: "haus"}, {Word: "hase"}, {Word: "see"}, {Word: "eis"}, {Word: "ast"}, {Word: "tee"}, {Word: "tasse"}, {Word: "seite"},
+	}
+	for _, name := range []string{"compact", "sparse"} {
+		t.Run(name, func(t *testing.T) {
+			objective := generators.Objectives[name]
+			score := func(retries int) float64 {
+				t.Helper()
+				res, err := crossword.Build(context.Background(), wordsAndHints, crossword.Options{Width: 10, MaxRetries: retries, Seed: 1, Objective: objective})
+				if err != nil {
+					t.Fatalf("Unexpected error: %s", err)
+				}
+				if res.Reason != generators.Complete {
+					t.Fatalf("Incorrect reason, got: %s, want: %s", res.Reason, generators.Complete)
+				}
+				if got, want := res.Score.Score(), objective.Score(res.Board.BestBoard); math.Abs(got-want) > 1e-9 {
+					t.Errorf("Incorrect score, got: %v, want: %v", got, want)
+				}
+				return res.Score.Score()
+			}
+			// The first attempt is the same, so the best of more is at least
+			// as good.
+			if first, best := score(1), score(10); best < first {
+				t.Errorf("Expected the best of 10 attempts to score at least as the first, got: %v, first: %v", best, first)
+			}
+		})
+	}
+}
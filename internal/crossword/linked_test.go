@@ -0,0 +1,34 @@
+package crossword
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+)
+
+func TestLinked(t *testing.T) {
+	haus := board.PlacedWord{Start: board.Location{X: 0, Y: 0}, Direction: board.Across, Word: "haus"}
+	see := board.PlacedWord{Start: board.Location{X: 2, Y: 0}, Direction: board.Down, Word: "see"}
+	tee := board.PlacedWord{Start: board.Location{X: 1, Y: 2}, Direction: board.Across, Word: "tee"}
+	ast := board.PlacedWord{Start: board.Location{X: 0, Y: 5}, Direction: board.Across, Word: "ast"}
+	eis := board.PlacedWord{Start: board.Location{X: 5, Y: 0}, Direction: board.Down, Word: "eis"}
+	tests := []struct {
+		name  string
+		words []board.PlacedWord
+		want  []board.PlacedWord
+	}{
+		{"none", nil, []board.PlacedWord{}},
+		{"all crossing", []board.PlacedWord{haus, see, tee}, []board.PlacedWord{haus, see, tee}},
+		{"island dropped", []board.PlacedWord{ast, haus, see, tee}, []board.PlacedWord{haus, see, tee}},
+		{"crossing dropped", []board.PlacedWord{haus, tee, eis}, []board.PlacedWord{haus}},
+		{"word order kept", []board.PlacedWord{tee, ast, see, haus}, []board.PlacedWord{tee, see, haus}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := linked(tt.words); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Incorrect words, got: %+v, want: %+v", got, tt.want)
+			}
+		})
+	}
+}
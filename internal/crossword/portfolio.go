@@ -0,0 +1,85 @@
+package crossword
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+
+	"github.com/Germanicus1/crizzcrozz/internal/generators"
+)
+
+// searchFunc runs one complete search, all attempts included, with the
+// random choices drawn from seed.
+type searchFunc func(ctx context.Context, seed int64) (*Result, error)
+
+// portfolio runs workers independent searches at the same time, each with
+// its own seed and so its own word order, first words and placements. The
+// first search to place every word cancels the others, which return the
+// best board they found so far. The result holds the best board of all
+// searches by the objective and the work they did together.
+func portfolio(ctx context.Context, workers int, seed int64, o generators.Objective, search searchFunc) (*Result, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		best     *Result
+		total    Result
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	for _, workerSeed := range workerSeeds(seed, workers) {
+		wg.Add(1)
+		go func(workerSeed int64) {
+			defer wg.Done()
+			res, err := search(ctx, workerSeed)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			total.Nodes += res.Nodes
+			total.Backtracks += res.Backtracks
+			if best == nil || isBetter(res, best, o) {
+				best = res
+			}
+			if res.Reason == generators.Complete {
+				cancel() // Every word is placed, the others can stop.
+			}
+		}(workerSeed)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	best.Nodes, best.Backtracks = total.Nodes, total.Backtracks
+	return best, nil
+}
+
+// isBetter reports whether the board of a holds more words than the board
+// of b, or as many with a higher score by the objective.
+func isBetter(a, b *Result, o generators.Objective) bool {
+	if b.Board == nil {
+		return a.Board != nil
+	}
+	return a.Board != nil && isBetterBoard(a.Board, b.Board, o)
+}
+
+// workerSeeds returns the seeds of the workers. The first worker uses the
+// seed itself, so a portfolio of one searches like a single search.
+func workerSeeds(seed int64, workers int) []int64 {
+	seeds := []int64{seed}
+	rng := rand.New(rand.NewSource(seed))
+	for len(seeds) < workers {
+		if s := rng.Int63(); s != 0 {
+			seeds = append(seeds, s)
+		}
+	}
+	return seeds
+}
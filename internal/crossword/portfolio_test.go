@@ -0,0 +1,144 @@
+package crossword
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/generators"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+)
+
+// resultWith returns a result with a board holding placed words.
+func resultWith(placed int, reason generators.Reason, seed int64) *Result {
+	bounds, _ := board.NewBoundsRectangle(3, 3)
+	b := board.NewBoard(bounds, 3, &board.OSFileWriter{})
+	b.BestWordCount = placed
+	b.Seed = seed
+	return &Result{Board: b, Reason: reason, Nodes: 1}
+}
+
+func TestPortfolio_CancelsOnCompleteSearch(t *testing.T) {
+	seeds := workerSeeds(42, 4)
+	winner := seeds[2]
+	var canceled atomic.Int32
+
+	search := func(ctx context.Context, seed int64) (*Result, error) {
+		if seed == winner {
+			return resultWith(3, generators.Complete, seed), nil
+		}
+		<-ctx.Done() // The others only stop when the winner cancels them.
+		canceled.Add(1)
+		return resultWith(1, generators.Canceled, seed), nil
+	}
+
+	res, err := portfolio(context.Background(), 4, 42, generators.DefaultObjective, search)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if res.Reason != generators.Complete || res.Board.Seed != winner {
+		t.Errorf("Incorrect result, got: %s with seed %d, want: complete with seed %d", res.Reason, res.Board.Seed, winner)
+	}
+	if got := canceled.Load(); got != 3 {
+		t.Errorf("Incorrect number of canceled searches, got: %d, want: 3", got)
+	}
+	if res.Nodes != 4 {
+		t.Errorf("Incorrect total nodes, got: %d, want: 4", res.Nodes)
+	}
+}
+
+// layoutWith returns a complete result with a board of haus and see, which
+// cross each other if crossed is true.
+func layoutWith(t *testing.T, crossed bool, seed int64) *Result {
+	t.Helper()
+	bounds, _ := board.NewBoundsRectangle(5, 5)
+	b := board.NewBoard(bounds, 2, &board.OSFileWriter{})
+	see := board.Location{X: 3, Y: 0}
+	if !crossed {
+		see = board.Location{X: 0, Y: 2}
+	}
+	if err := b.PlaceEntryAt(board.Location{}, words.Entry{Word: "haus"}, board.Across); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := b.PlaceEntryAt(see, words.Entry{Word: "see"}, board.Down); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	b.SaveBestSolution()
+	b.Seed = seed
+	return &Result{Board: b, Reason: generators.Complete, Nodes: 1}
+}
+
+// TestPortfolio_KeepsBestCompleteResult checks that the first complete
+// board cancels the other searches, and that a board with a higher score
+// one of them found before it stopped still wins.
+func TestPortfolio_KeepsBestCompleteResult(t *testing.T) {
+	seeds := workerSeeds(3, 2)
+	o := generators.DefaultObjective
+	if low, high := layoutWith(t, false, 0), layoutWith(t, true, 0); o.Score(low.Board.BestBoard) >= o.Score(high.Board.BestBoard) {
+		t.Fatalf("Expected crossing words to score higher")
+	}
+
+	search := func(ctx context.Context, seed int64) (*Result, error) {
+		if seed == seeds[0] {
+			return layoutWith(t, false, seed), nil
+		}
+		<-ctx.Done() // Still improving its board when the first one cancels it.
+		return layoutWith(t, true, seed), nil
+	}
+
+	res, err := portfolio(context.Background(), 2, 3, o, search)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if res.Reason != generators.Complete || res.Board.Seed != seeds[1] {
+		t.Errorf("Incorrect result, got: %s with seed %d, want: complete with seed %d", res.Reason, res.Board.Seed, seeds[1])
+	}
+}
+
+func TestPortfolio_KeepsBestPartialResult(t *testing.T) {
+	seeds := workerSeeds(7, 3)
+	placed := map[int64]int{seeds[0]: 1, seeds[1]: 2, seeds[2]: 1}
+
+	search := func(_ context.Context, seed int64) (*Result, error) {
+		return resultWith(placed[seed], generators.Exhausted, seed), nil
+	}
+
+	res, err := portfolio(context.Background(), 3, 7, generators.DefaultObjective, search)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if res.Board.BestWordCount != 2 || res.Board.Seed != seeds[1] {
+		t.Errorf("Incorrect best result, got: %d words with seed %d, want: 2 with seed %d", res.Board.BestWordCount, res.Board.Seed, seeds[1])
+	}
+}
+
+func TestPortfolio_Error(t *testing.T) {
+	want := errors.New("broken")
+	search := func(ctx context.Context, seed int64) (*Result, error) {
+		if seed == 1 {
+			return nil, want
+		}
+		<-ctx.Done()
+		return resultWith(0, generators.Canceled, seed), nil
+	}
+
+	if _, err := portfolio(context.Background(), 2, 1, generators.DefaultObjective, search); !errors.Is(err, want) {
+		t.Errorf("Incorrect error, got: %v, want: %v", err, want)
+	}
+}
+
+func TestWorkerSeeds(t *testing.T) {
+	seeds := workerSeeds(42, 5)
+	if len(seeds) != 5 || seeds[0] != 42 {
+		t.Fatalf("Incorrect seeds, got: %v", seeds)
+	}
+	seen := make(map[int64]bool)
+	for _, s := range seeds {
+		if s == 0 || seen[s] {
+			t.Errorf("Expected distinct non-zero seeds, got: %v", seeds)
+		}
+		seen[s] = true
+	}
+}
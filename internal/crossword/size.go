@@ -0,0 +1,434 @@
+package crossword
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/generators"
+	"github.com/Germanicus1/crizzcrozz/internal/parse"
+	"github.com/Germanicus1/crizzcrozz/pkg/models"
+)
+
+// The settings of FindSmallestSize.
+const (
+	DefaultSizeTimeout = time.Minute // The time budget of a search without a deadline.
+	maxAspect          = 2.0         // The max ratio of the longer side to the shorter one without an aspect ratio.
+	sizeGrowth         = 1.25        // How much larger the next size is while no size fits.
+	maxGrowth          = 8           // The max number of larger sizes tried.
+	maxSide            = 1000        // The max number of cells a side of the boards tried.
+)
+
+// ErrNoSizeFound is returned when no board size that fits all words was
+// found in time.
+var ErrNoSizeFound = errors.New("no board size found that fits all words")
+
+// SizeTrial records what building the crossword on a board of one size
+// achieved.
+type SizeTrial struct {
+	Width, Height int
+	Placed, Total int               // The number of words placed and to place.
+	Reason        generators.Reason // Why the build stopped; Complete if all words were placed.
+	// Grid is the rectangle around the letters of the board, if any were
+	// placed.
+	Grid board.Size
+	// Reused is the number of words of an earlier layout the build started
+	// from, zero if it started from an empty board.
+	Reused int
+}
+
+// SizeResult is the outcome of FindSmallestSize.
+type SizeResult struct {
+	*Result             // The build on the smallest board found, nil if none was.
+	Trials  []SizeTrial // The sizes tried, in the order they were tried.
+}
+
+// FindSmallestSize searches for the smallest board, by area, that fits all
+// the words, and returns the build on it. With an aspect ratio, the ratio
+// of width to height like 1 for square boards, only boards of that ratio
+// are tried, and a board is cropped to its letters only if they keep it;
+// without one, boards up to twice as wide as high or the other way round
+// are tried, and the boards are cropped to their letters.
+//
+// The search starts from the size EstimateInitialBoardSize gives and grows
+// it until all words fit. Then it tries smaller sizes, halving the sizes
+// left to try with each build. Each build narrows the sizes left: a board
+// on which the search runs out of placements without fitting all words
+// rules out the boards that fit inside it, while one on which it runs out
+// of budget rules out only itself, and a board that fits all words is
+// cropped to its letters, which proves the smaller size without another
+// build. The search stops when no smaller size is left or ctx is done, and
+// after DefaultSizeTimeout if ctx has no deadline. The options are used for
+// every build, except for their width, height and crop. Masks give the
+// board a fixed size and cannot be used.
+//
+// With the default generator and no pinned words, each build starts from
+// the last layout: the largest group of crossing words of the last complete
+// one, or of the last partial one while none is complete, that fits the new
+// size is pinned where it was, and only the other words are placed. If that
+// does not place all words, the size is built again from an empty board, so
+// a size is only ruled out by a build from scratch.
+//
+// If no size fitting all words was found, ErrNoSizeFound is returned
+// together with the sizes tried.
+func FindSmallestSize(ctx context.Context, wordsAndHints []*models.WordsAndHints, opts Options, aspect float64) (*SizeResult, error) {
+	if opts.Mask != nil {
+		return nil, errors.New("masks set the size of the board, it cannot be searched")
+	}
+	if aspect < 0 {
+		return nil, errors.New("the aspect ratio must be positive")
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultSizeTimeout)
+		defer cancel()
+	}
+	pinned, err := parse.CleanPinnedWords(opts.Pinned)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", generators.ErrInvalidPin, err)
+	}
+	entries := addPinnedWords(parse.CleanWords(wordsAndHints), pinned)
+	if len(entries) == 0 {
+		return nil, errors.New("no words given")
+	}
+
+	// No board is narrower than the longest word, as the generators place
+	// the first word across, nor has fewer cells than half the letters, as
+	// a cell holds the letters of two words at most.
+	longest, letters := 0, 0
+	for _, e := range entries {
+		longest = max(longest, e.Length())
+		letters += e.Length()
+	}
+	minArea := (letters + 1) / 2
+	fits := func(s size) bool {
+		if s.width < longest || s.area() < minArea {
+			return false
+		}
+		for _, p := range pinned {
+			if end := wordEnd(p); end.X >= s.width || end.Y >= s.height {
+				return false
+			}
+		}
+		return true
+	}
+
+	search := &sizeSearch{opts: opts, aspect: aspect, total: len(entries), result: &SizeResult{}, tried: make(map[size]bool)}
+	search.opts.Crop = false
+	search.reuse = len(pinned) == 0 && (opts.Generator == "" || opts.Generator == generators.Asymmetrical)
+
+	// Grow the estimated size until all words fit.
+	estimate := EstimateInitialBoardSize(entries)
+	for i := 0; i < maxGrowth && search.best == nil; i++ {
+		s := search.shape(estimate)
+		for !fits(s) {
+			if estimate >= maxSide || ctx.Err() != nil {
+				return search.done(nil)
+			}
+			estimate++
+			s = search.shape(estimate)
+		}
+		if err := search.try(ctx, wordsAndHints, s); err != nil {
+			return search.done(err)
+		}
+		if ctx.Err() != nil {
+			return search.done(nil)
+		}
+		estimate = max(estimate+1, int(float64(estimate)*sizeGrowth))
+	}
+	if search.best == nil {
+		return search.done(nil)
+	}
+
+	// Shrink it, trying the size in the middle of those left.
+	for ctx.Err() == nil {
+		var left []size
+		for _, s := range search.smaller() {
+			if fits(s) && !search.tried[s] && !search.ruledOut(s) {
+				left = append(left, s)
+			}
+		}
+		if len(left) == 0 {
+			break
+		}
+		if err := search.try(ctx, wordsAndHints, left[len(left)/2]); err != nil {
+			return search.done(err)
+		}
+	}
+	return search.done(nil)
+}
+
+// size is the width and height of a board.
+type size struct {
+	width, height int
+}
+
+func (s size) area() int {
+	return s.width * s.height
+}
+
+// sizeSearch holds the state of a call to FindSmallestSize.
+type sizeSearch struct {
+	opts   Options
+	aspect float64
+	total  int // The number of words to place.
+	result *SizeResult
+	best   *size         // The smallest size that fits all words so far.
+	failed []size        // The sizes that ran out of placements without fitting all words.
+	tried  map[size]bool // The sizes built so far.
+	// reuse tells whether builds start from the last layout, which they
+	// pin; only the default generator places pinned words.
+	reuse bool
+	// layout is the last layout to start from, with the rectangle around
+	// its letters of the given size starting at 0, 0.
+	layout     []board.PlacedWord
+	layoutSize size
+}
+
+// shape returns the size of a board of about n by n cells, with the aspect
+// ratio of the search if it has one.
+func (s *sizeSearch) shape(n int) size {
+	if s.aspect == 0 {
+		return size{n, n}
+	}
+	width := max(1, int(math.Round(float64(n)*math.Sqrt(s.aspect))))
+	return size{width, s.height(width)}
+}
+
+// height returns the height of a board of the width with the aspect ratio
+// of the search.
+func (s *sizeSearch) height(width int) int {
+	return max(1, int(math.Round(float64(width)/s.aspect)))
+}
+
+// smaller returns the sizes with fewer cells than the best one, by area
+// and then from the most square to the least.
+func (s *sizeSearch) smaller() []size {
+	var sizes []size
+	for w := 1; w < s.best.area(); w++ {
+		if s.aspect > 0 {
+			if h := s.height(w); w*h < s.best.area() {
+				sizes = append(sizes, size{w, h})
+			}
+			continue
+		}
+		for h := 1; w*h < s.best.area(); h++ {
+			if float64(max(w, h)) <= maxAspect*float64(min(w, h)) {
+				sizes = append(sizes, size{w, h})
+			}
+		}
+	}
+	sort.SliceStable(sizes, func(i, j int) bool {
+		if sizes[i].area() != sizes[j].area() {
+			return sizes[i].area() < sizes[j].area()
+		}
+		return skew(sizes[i]) < skew(sizes[j])
+	})
+	return sizes
+}
+
+// ruledOut reports whether the size fits inside one on which the search
+// ran out of placements. A search that ran out of budget proves nothing
+// about smaller sizes.
+func (s *sizeSearch) ruledOut(sz size) bool {
+	for _, f := range s.failed {
+		if sz.width <= f.width && sz.height <= f.height {
+			return true
+		}
+	}
+	return false
+}
+
+// skew returns how much longer the longer side of the size is than the
+// shorter one.
+func skew(sz size) int {
+	return max(sz.width, sz.height) - min(sz.width, sz.height)
+}
+
+// try builds the crossword on a board of the size and records the outcome.
+// It starts from the last layout if it can, and builds from scratch if that
+// does not place all words. It returns an error only if the build cannot
+// run.
+func (s *sizeSearch) try(ctx context.Context, wordsAndHints []*models.WordsAndHints, sz size) error {
+	opts := s.opts
+	opts.Width, opts.Height = sz.width, sz.height
+	s.tried[sz] = true
+	trial := SizeTrial{Width: sz.width, Height: sz.height, Total: s.total}
+
+	var res *Result
+	var err error
+	if start := s.start(sz); len(start) > 0 {
+		res, err = build(ctx, wordsAndHints, opts, start)
+		if err == nil && res.Reason == generators.Complete {
+			trial.Reused = len(start)
+		} else {
+			res, err = nil, nil
+		}
+	}
+	if res == nil {
+		res, err = Build(ctx, wordsAndHints, opts)
+	}
+	switch {
+	case errors.Is(err, ErrNoWordsPlaced):
+		trial.Reason = generators.Exhausted
+	case ctx.Err() != nil && (err != nil || res.Reason.Stopped()):
+		// The search ran out of time; the size was not ruled out.
+		if res != nil {
+			trial.Placed, trial.Total, trial.Reason = res.Board.BestWordCount, res.Board.TotalWords, res.Reason
+		} else {
+			trial.Reason = generators.ContextReason(ctx.Err())
+		}
+		s.result.Trials = append(s.result.Trials, trial)
+		return nil
+	case err != nil:
+		return err
+	default:
+		trial.Placed, trial.Total, trial.Reason, trial.Grid = res.Board.BestWordCount, res.Board.TotalWords, res.Reason, res.Size
+	}
+	s.result.Trials = append(s.result.Trials, trial)
+	if res != nil && (trial.Reason == generators.Complete || s.best == nil) {
+		s.keepLayout(res.Board)
+	}
+
+	if trial.Reason != generators.Complete {
+		if trial.Reason == generators.Exhausted {
+			s.failed = append(s.failed, sz)
+		}
+		return nil
+	}
+	found := sz
+	if grid := (size{res.Size.Width, res.Size.Height}); s.aspect == 0 || grid.height == s.height(grid.width) {
+		// The letters fit a smaller board the search may return.
+		res.Board.Crop()
+		found = grid
+	}
+	if s.best == nil || found.area() < s.best.area() {
+		s.best = &found
+		s.result.Result = res
+	}
+	return nil
+}
+
+// keepLayout keeps the words of the best solution of the board as the
+// layout the next builds start from.
+func (s *sizeSearch) keepLayout(b *board.Board) {
+	if !s.reuse {
+		return
+	}
+	grid, from := b.BestSize()
+	s.layout = s.layout[:0]
+	for _, p := range b.BestPlacedWords {
+		p.Start = board.Location{X: p.Start.X - from.X, Y: p.Start.Y - from.Y}
+		s.layout = append(s.layout, p)
+	}
+	s.layoutSize = size{grid.Width, grid.Height}
+}
+
+// start returns the words of the last layout to pin on a board of the size,
+// where they were relative to each other. A layout smaller than the board
+// is centered on it; of a larger one, the part of the size that holds the
+// most whole words is taken. Pinned words do not have to cross, so only the
+// largest group of words crossing each other is kept, or the board could
+// end up in pieces. Nil if no words fit.
+func (s *sizeSearch) start(sz size) []board.PlacedWord {
+	if len(s.layout) == 0 {
+		return nil
+	}
+	var best []board.PlacedWord
+	for _, dx := range shifts(s.layoutSize.width, sz.width) {
+		for _, dy := range shifts(s.layoutSize.height, sz.height) {
+			var words []board.PlacedWord
+			for _, p := range s.layout {
+				p.Start = board.Location{X: p.Start.X + dx, Y: p.Start.Y + dy}
+				if end := wordEnd(p); p.Start.X >= 0 && p.Start.Y >= 0 && end.X < sz.width && end.Y < sz.height {
+					words = append(words, p)
+				}
+			}
+			if words = linked(words); len(words) > len(best) {
+				best = words
+			}
+		}
+	}
+	return best
+}
+
+// linked returns the largest group of the words that are linked to each
+// other through words crossing them, in the order of the words.
+func linked(words []board.PlacedWord) []board.PlacedWord {
+	owners := make(map[board.Location][]int) // The words on each cell.
+	for i, p := range words {
+		for _, l := range wordCells(p) {
+			owners[l] = append(owners[l], i)
+		}
+	}
+	grouped := make([]bool, len(words))
+	var largest []int
+	for i := range words {
+		if grouped[i] {
+			continue
+		}
+		grouped[i] = true
+		group := []int{i}
+		for k := 0; k < len(group); k++ {
+			for _, l := range wordCells(words[group[k]]) {
+				for _, other := range owners[l] {
+					if !grouped[other] {
+						grouped[other] = true
+						group = append(group, other)
+					}
+				}
+			}
+		}
+		if len(group) > len(largest) {
+			largest = group
+		}
+	}
+	sort.Ints(largest)
+	linked := make([]board.PlacedWord, len(largest))
+	for i, w := range largest {
+		linked[i] = words[w]
+	}
+	return linked
+}
+
+// wordCells returns the cells of the placed word.
+func wordCells(p board.PlacedWord) []board.Location {
+	end := wordEnd(p)
+	var cells []board.Location
+	for y := p.Start.Y; y <= end.Y; y++ {
+		for x := p.Start.X; x <= end.X; x++ {
+			cells = append(cells, board.Location{X: x, Y: y})
+		}
+	}
+	return cells
+}
+
+// shifts returns the shifts that move a layout of the given length onto a
+// board of the given length: the one that centers it if it is shorter, and
+// all those that keep the board within it otherwise.
+func shifts(layout, length int) []int {
+	if layout <= length {
+		return []int{(length - layout) / 2}
+	}
+	var shifts []int
+	for start := 0; start <= layout-length; start++ {
+		shifts = append(shifts, -start)
+	}
+	return shifts
+}
+
+// done returns the result of the search, with ErrNoSizeFound if no size
+// fits all words, or err if it is set.
+func (s *sizeSearch) done(err error) (*SizeResult, error) {
+	if err != nil {
+		return nil, err
+	}
+	if s.result.Result == nil {
+		return s.result, ErrNoSizeFound
+	}
+	return s.result, nil
+}
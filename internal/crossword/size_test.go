@@ -0,0 +1,163 @@
+package crossword_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/crossword"
+	"github.com/Germanicus1/crizzcrozz/internal/generators"
+	"github.com/Germanicus1/crizzcrozz/pkg/models"
+)
+
+func TestFindSmallestSize(t *testing.T) {
+	wordsAndHints := []*models.WordsAndHints{
+		{Word: "haus"}, {Word: "hase"}, {Word: "see"}, {Word: "eis"}, {Word: "ast"}, {Word: "tee"},
+	}
+	tests := []struct {
+		name   string
+		aspect float64
+	}{
+		{"any ratio", 0},
+		{"square", 1},
+		{"wide", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := crossword.FindSmallestSize(context.Background(), wordsAndHints, crossword.Options{MaxRetries: 3, Seed: 1}, tt.aspect)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			b := res.Board
+			if res.Reason != generators.Complete || b.BestWordCount != len(wordsAndHints) {
+				t.Fatalf("Incorrect result, got: %d words %s, want: %d words %s", b.BestWordCount, res.Reason, len(wordsAndHints), generators.Complete)
+			}
+			area := b.Bounds.Width() * b.Bounds.Height()
+			for _, trial := range res.Trials {
+				if trial.Reason == generators.Complete && trial.Grid.Area() < area && tt.aspect == 0 {
+					t.Errorf("Expected the smallest size found, got: %dx%d, found: %dx%d", b.Bounds.Width(), b.Bounds.Height(), trial.Grid.Width, trial.Grid.Height)
+				}
+				if tt.aspect > 0 && trial.Height != max(1, int(math.Round(float64(trial.Width)/tt.aspect))) {
+					t.Errorf("Expected sizes of the aspect ratio %v, got: %dx%d", tt.aspect, trial.Width, trial.Height)
+				}
+			}
+			if tt.aspect == 0 && (res.Size.Width != b.Bounds.Width() || res.Size.Height != b.Bounds.Height()) {
+				t.Errorf("Expected a cropped board, got: %dx%d with letters in %dx%d", b.Bounds.Width(), b.Bounds.Height(), res.Size.Width, res.Size.Height)
+			}
+		})
+	}
+}
+
+// TestFindSmallestSize_Reuse checks that builds start from the last layout
+// with the default generator, and from scratch with the others, which
+// cannot place pinned words.
+func TestFindSmallestSize_Reuse(t *testing.T) {
+	wordsAndHints := []*models.WordsAndHints{
+		{Word: "haus"}, {Word: "hase"}, {Word: "see"}, {Word: "eis"}, {Word: "ast"}, {Word: "tee"}, {Word: "tisch"}, {Word: "stuhl"},
+	}
+	for _, tt := range []struct {
+		generator string
+		wantReuse bool
+	}{
+		{generators.Asymmetrical, true},
+		{generators.CSP, false},
+	} {
+		t.Run(tt.generator, func(t *testing.T) {
+			res, err := crossword.FindSmallestSize(context.Background(), wordsAndHints, crossword.Options{Generator: tt.generator, MaxRetries: 3, Seed: 1}, 0)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			reused := false
+			for _, trial := range res.Trials {
+				reused = reused || trial.Reused > 0
+				if trial.Reused > 0 && trial.Reason != generators.Complete {
+					t.Errorf("Expected only complete builds to report reused words, got: %+v", trial)
+				}
+			}
+			if reused != tt.wantReuse {
+				t.Errorf("Incorrect reuse, got: %v, want: %v (trials: %+v)", reused, tt.wantReuse, res.Trials)
+			}
+			if b := res.Board; res.Reason != generators.Complete || b.BestWordCount != len(wordsAndHints) {
+				t.Errorf("Incorrect result, got: %d words %s, want: %d words %s", b.BestWordCount, res.Reason, len(wordsAndHints), generators.Complete)
+			}
+		})
+	}
+}
+
+// TestFindSmallestSize_Budget checks that a build that runs out of budget
+// does not rule out the smaller sizes, and that no size is built twice.
+func TestFindSmallestSize_Budget(t *testing.T) {
+	wordsAndHints := []*models.WordsAndHints{
+		{Word: "haus"}, {Word: "hase"}, {Word: "see"}, {Word: "eis"}, {Word: "ast"}, {Word: "tee"}, {Word: "tisch"}, {Word: "stuhl"},
+	}
+	opts := crossword.Options{Generator: generators.CSP, MaxRetries: 1, Seed: 1, Budget: generators.Budget{MaxBacktracks: 3}}
+	res, err := crossword.FindSmallestSize(context.Background(), wordsAndHints, opts, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	retried := false
+	for i, trial := range res.Trials {
+		for _, earlier := range res.Trials[:i] {
+			if trial.Width == earlier.Width && trial.Height == earlier.Height {
+				t.Errorf("Expected each size built once, got: %dx%d twice", trial.Width, trial.Height)
+			}
+			if trial.Width > earlier.Width || trial.Height > earlier.Height || earlier.Reason == generators.Complete {
+				continue
+			}
+			if earlier.Reason == generators.Exhausted {
+				t.Errorf("Expected %dx%d ruled out by the exhausted %dx%d", trial.Width, trial.Height, earlier.Width, earlier.Height)
+			}
+			retried = true
+		}
+	}
+	if !retried {
+		t.Errorf("Expected smaller sizes built after a build out of budget, got: %+v", res.Trials)
+	}
+}
+
+func TestFindSmallestSize_Errors(t *testing.T) {
+	wordsAndHints := []*models.WordsAndHints{{Word: "haus"}, {Word: "see"}}
+	mask, err := board.NewMask(5, 5)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if _, err := crossword.FindSmallestSize(context.Background(), wordsAndHints, crossword.Options{Mask: mask}, 0); err == nil {
+		t.Errorf("Expected an error with a mask")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res, err := crossword.FindSmallestSize(ctx, wordsAndHints, crossword.Options{Seed: 1}, 0)
+	if !errors.Is(err, crossword.ErrNoSizeFound) {
+		t.Fatalf("Incorrect error, got: %v, want: %v", err, crossword.ErrNoSizeFound)
+	}
+	if len(res.Trials) != 1 || res.Trials[0].Reason != generators.Canceled {
+		t.Errorf("Incorrect trials, got: %+v, want: one canceled", res.Trials)
+	}
+}
+
+// TestFindSmallestSize_InvalidPin checks that a pinned word no board can
+// hold is rejected rather than grown around.
+func TestFindSmallestSize_InvalidPin(t *testing.T) {
+	wordsAndHints := []*models.WordsAndHints{{Word: "haus"}, {Word: "see"}}
+	pinned := []*models.PinnedWord{{Word: "haus", X: -1, Direction: "across"}}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := crossword.FindSmallestSize(ctx, wordsAndHints, crossword.Options{Seed: 1, Pinned: pinned}, 0)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, generators.ErrInvalidPin) {
+			t.Errorf("Incorrect error, got: %v, want: %v", err, generators.ErrInvalidPin)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Expected the search to stop, it still runs after its deadline")
+	}
+}
@@ -0,0 +1,187 @@
+package generators
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+)
+
+// The defaults of the AmericanGenerator settings.
+const (
+	DefaultAmericanMaxBlack = 1.0 / 6 // Share of black squares newspapers allow.
+	americanMinLength       = 3       // Letters of the shortest word.
+	themeTries              = 20      // Positions tried per theme word and pattern.
+)
+
+// AmericanGenerator composes newspaper style grids: the pattern of black
+// squares looks the same when the board is turned by 180 degrees, every
+// white cell is part of an across and a down word, every word has at least
+// three letters, and the white cells form a single area. The cells the
+// mask of the board blocks are black squares, and so are their mirrors.
+//
+// The words of the pool are the theme words, which are all placed across
+// before the grid is filled. The rest of the grid is filled from the pool
+// and the Dictionary like the DenseGenerator does, every word at most once.
+//
+// A complete board is one without empty slots; its TotalWords is set to the
+// number of words in the grid.
+type AmericanGenerator struct {
+	*BaseGenerator
+	WordPool *words.Pool
+	// Rand draws the patterns, the positions of the theme words and shuffles
+	// the words tried for each slot. Without it, patterns and positions are
+	// drawn from a fixed seed and the words are tried in pool order.
+	Rand   *rand.Rand
+	Budget Budget // Limits the work of each call to Generate.
+	// Dictionary holds the words to fill the grid with besides the theme
+	// words.
+	Dictionary []words.Entry
+	// Objective decides between partial grids with as many words which one
+	// is saved as the best solution.
+	Objective Objective
+
+	MaxBlack          float64 // The max share of black squares, between 0 and 1.
+	Patterns          int     // The max number of patterns tried per call to Generate.
+	PatternBacktracks int     // The max number of backtracks per pattern; no limit if zero.
+
+	run // The state of the current call to Generate.
+}
+
+// NewAmericanGenerator returns a newspaper style grid generator with the
+// default settings.
+func NewAmericanGenerator(board *board.Board, pool *words.Pool, rng *rand.Rand) *AmericanGenerator {
+	return &AmericanGenerator{
+		BaseGenerator:     NewBaseGenerator(board),
+		WordPool:          pool,
+		Rand:              rng,
+		Objective:         DefaultObjective,
+		MaxBlack:          DefaultAmericanMaxBlack,
+		Patterns:          DefaultPatterns,
+		PatternBacktracks: DefaultPatternBacktracks,
+	}
+}
+
+// Generate composes a grid with the theme words of the pool. It stops early
+// when ctx is done or the budget runs out. The best grid found is saved as
+// the best solution of the board, and the board is left empty unless the
+// grid is complete, so Generate can be called again for another attempt.
+func (ag *AmericanGenerator) Generate(ctx context.Context) (Result, error) {
+	if ag.Board == nil || len(ag.WordPool.Entries) == 0 {
+		return Result{}, fmt.Errorf("uninitialized board or pool, or empty words list")
+	}
+	ag.start(ctx, ag.Budget)
+	rng := ag.Rand
+	if rng == nil {
+		rng = rand.New(rand.NewSource(1))
+	}
+
+	w := ag.Board.Bounds.Width()
+	empty := maskGrid(ag.Board, true)
+	masked := empty.blackCount()
+	entries := mergeEntries(ag.WordPool.Entries, ag.Dictionary)
+	index := make(map[string]int, len(entries))
+	for i, entry := range entries {
+		index[entry.Word] = i
+	}
+	var themes []int
+	for _, entry := range ag.WordPool.Entries {
+		if entry.Length() < americanMinLength || entry.Length() > w {
+			return Result{Reason: Exhausted}, nil
+		}
+		themes = append(themes, index[entry.Word])
+	}
+
+	rules := gridRules{
+		maxBlack:  masked + int(ag.MaxBlack*float64(len(empty.black)-masked)),
+		minLength: americanMinLength,
+		lengths:   entryLengths(entries),
+		checked:   true,
+		symmetric: true,
+	}
+	reason := ag.compose(composer{
+		board:             ag.Board,
+		entries:           entries,
+		rng:               ag.Rand,
+		objective:         ag.Objective,
+		patterns:          ag.Patterns,
+		patternBacktracks: ag.PatternBacktracks,
+		draw: func() (*grid, []fixedWord, bool) {
+			base, white, fixed, ok := placeThemes(rng, empty, entries, themes)
+			if !ok {
+				return nil, nil, false
+			}
+			g, ok := randomGrid(rng, base, white, rules)
+			return g, fixed, ok
+		},
+	})
+	return ag.result(ag.Board, reason), nil
+}
+
+// placeThemes puts the theme words across at random positions of a copy of
+// the base grid. It returns the grid with the black squares ending the theme
+// words and their mirrors, the cells that have to stay white, and the theme
+// words fixed in their slots. It reports false if a theme word found no
+// position.
+func placeThemes(rng *rand.Rand, base *grid, entries []words.Entry, themes []int) (*grid, []bool, []fixedWord, bool) {
+	w, h := base.w, base.h
+	g := &grid{w: w, h: h, black: append([]bool(nil), base.black...)}
+	white := make([]bool, w*h)
+	taken := make([]bool, w*h) // The cells holding a letter of a theme word.
+	var fixed []fixedWord
+	for _, theme := range themes {
+		n := entries[theme].Length()
+		placed := false
+		for try := 0; try < themeTries && !placed; try++ {
+			x, y := rng.Intn(w-n+1), rng.Intn(h)
+			placed = placeTheme(g, white, taken, x, y, n)
+			if placed {
+				fixed = append(fixed, fixedWord{
+					Placement: Placement{Start: board.Location{X: x, Y: y}, Direction: board.Across},
+					entry:     theme,
+				})
+			}
+		}
+		if !placed {
+			return nil, nil, nil, false
+		}
+	}
+	return g, white, fixed, true
+}
+
+// placeTheme reserves the across slot of n cells at x, y for a theme word,
+// if its cells and their mirrors can stay white, no other theme word uses
+// its cells, and the cells before and after it can turn black.
+func placeTheme(g *grid, white, taken []bool, x, y, n int) bool {
+	var ends []int
+	if x > 0 {
+		ends = append(ends, y*g.w+x-1)
+	}
+	if x+n < g.w {
+		ends = append(ends, y*g.w+x+n)
+	}
+	cells := make(map[int]bool, 2*n) // The cells of the slot and their mirrors.
+	for i := 0; i < n; i++ {
+		c := y*g.w + x + i
+		if taken[c] || g.black[c] || g.black[g.mirror(c)] {
+			return false
+		}
+		cells[c], cells[g.mirror(c)] = true, true
+	}
+	for _, c := range ends {
+		if white[c] || white[g.mirror(c)] || cells[c] {
+			return false
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		c := y*g.w + x + i
+		white[c], white[g.mirror(c)], taken[c] = true, true, true
+	}
+	for _, c := range ends {
+		g.setBlack(c, true)
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package generators_test
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/generators"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+)
+
+func TestAmericanGenerator_Themes(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	dictionary := letterGrid(rng, 5)
+	tests := []struct {
+		name       string
+		themes     []string
+		wantReason generators.Reason
+	}{
+		{
+			name:       "complete",
+			themes:     dictionary[:1], // The first row of the letter grid.
+			wantReason: generators.Complete,
+		},
+		{
+			name:       "theme too short",
+			themes:     []string{"ab"},
+			wantReason: generators.Exhausted,
+		},
+		{
+			name:       "theme too long",
+			themes:     []string{"abcdef"},
+			wantReason: generators.Exhausted,
+		},
+		{
+			name:       "theme does not fit",
+			themes:     []string{"zzzzz"},
+			wantReason: generators.Exhausted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bounds, err := board.NewBoundsRectangle(5, 5)
+			if err != nil {
+				t.Fatalf("Failed to create bounds: %s", err)
+			}
+			b := board.NewBoard(bounds, len(tt.themes), &board.OSFileWriter{})
+			pool := words.NewPool()
+			pool.LoadWords(tt.themes)
+			var entries []words.Entry
+			for _, w := range dictionary {
+				entries = append(entries, words.Entry{Word: w})
+			}
+			g, err := generators.New(generators.American, b, pool, generators.Options{
+				Rand:       rand.New(rand.NewSource(1)),
+				Dictionary: entries,
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			res, err := g.Generate(context.Background())
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if res.Reason != tt.wantReason {
+				t.Fatalf("Incorrect reason, got: %s, want: %s", res.Reason, tt.wantReason)
+			}
+			if tt.wantReason != generators.Complete {
+				if b.WordCount != 0 {
+					t.Errorf("Expected an empty board after an incomplete run, got: %d words", b.WordCount)
+				}
+				return
+			}
+			if b.BestWordCount != 10 || b.TotalWords != 10 {
+				t.Errorf("Incorrect number of words, got: %d of %d, want: 10 of 10", b.BestWordCount, b.TotalWords)
+			}
+			found := false
+			for _, p := range b.BestPlacedWords {
+				found = found || (p.Word == tt.themes[0] && p.Direction == board.Across)
+			}
+			if !found {
+				t.Errorf("Expected theme word %s across, got: %+v", tt.themes[0], b.BestPlacedWords)
+			}
+			checkLayout(t, b)
+		})
+	}
+}
@@ -0,0 +1,408 @@
+package generators
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+)
+
+// The defaults of the AnnealingGenerator settings.
+const (
+	DefaultSteps            = 3000 // Moves per call to Generate.
+	DefaultStartTemperature = 1.0  // Accepts losing a word with a chance of about 1 in 3 at first.
+	DefaultEndTemperature   = 0.02 // Accepts hardly any worse layout at the end.
+	DefaultMaxRemove        = 3    // Words removed per move.
+)
+
+// AnnealingGenerator improves a layout by local search with simulated
+// annealing, for word lists that do not all fit on the board. It starts
+// from the words on the board, or else from the best solution of the board,
+// so each call to Generate goes on from the best layout of the last one; an
+// empty board starts from a first word across the middle. The words off the
+// board are inserted greedily, then every move removes a few random words,
+// together with the words no longer connected to the rest, and inserts as
+// many of the words off the board as fit again. A move that makes the
+// layout better by the Objective is kept; a worse one is kept with a chance
+// that shrinks as the temperature cools down from StartTemperature to
+// EndTemperature, so the search can leave a layout the backtracking
+// generators get stuck in.
+//
+// It runs all Steps even when all words are placed, to improve the score of
+// the layout, unless the Objective weighs only the words.
+type AnnealingGenerator struct {
+	*BaseGenerator
+	WordPool *words.Pool
+	// Rand picks the words to remove, the order to insert them in and
+	// whether a worse layout is kept. Without it, the choices are drawn from
+	// a fixed seed.
+	Rand      *rand.Rand
+	Budget    Budget    // Limits the work of each call to Generate.
+	Objective Objective // What makes a layout good.
+
+	Steps            int     // The number of moves per call to Generate.
+	StartTemperature float64 // The temperature of the first move.
+	EndTemperature   float64 // The temperature of the last move.
+	MaxRemove        int     // The max number of random words removed per move.
+
+	run // The state of the current call to Generate.
+}
+
+// NewAnnealingGenerator returns a simulated annealing generator with the
+// default settings.
+func NewAnnealingGenerator(board *board.Board, pool *words.Pool, rng *rand.Rand) *AnnealingGenerator {
+	return &AnnealingGenerator{
+		BaseGenerator:    NewBaseGenerator(board),
+		WordPool:         pool,
+		Rand:             rng,
+		Objective:        DefaultObjective,
+		Steps:            DefaultSteps,
+		StartTemperature: DefaultStartTemperature,
+		EndTemperature:   DefaultEndTemperature,
+		MaxRemove:        DefaultMaxRemove,
+	}
+}
+
+// layout records where the words are, by index in the pool, so a move can
+// be taken back.
+type layout struct {
+	placed []bool
+	at     []Placement
+}
+
+// annealer holds the state of a single call to Generate.
+type annealer struct {
+	*run
+	board   *board.Board
+	entries []words.Entry
+	rng     *rand.Rand
+	layout
+}
+
+// Generate places as many words of the pool on the board as it can,
+// starting from the words on the board if there are any. It stops early
+// when ctx is done or the budget runs out. The best layout
+// found is saved as the best solution of the board, and the board is left
+// empty unless all words were placed, so Generate can be called again for
+// another attempt.
+func (ag *AnnealingGenerator) Generate(ctx context.Context) (Result, error) {
+	if ag.Board == nil || len(ag.WordPool.Entries) == 0 {
+		return Result{}, fmt.Errorf("uninitialized board or pool, or empty words list")
+	}
+	ag.start(ctx, ag.Budget)
+	rng := ag.Rand
+	if rng == nil {
+		rng = rand.New(rand.NewSource(1))
+	}
+	entries := ag.WordPool.Entries
+	if ag.Rand != nil {
+		shuffleTies(ag.Rand, entries)
+		pickFirstWord(ag.Rand, entries)
+	}
+
+	a := &annealer{
+		run:     &ag.run,
+		board:   ag.Board,
+		entries: entries,
+		rng:     rng,
+		layout:  layout{placed: make([]bool, len(entries)), at: make([]Placement, len(entries))},
+	}
+	if err := a.startLayout(); err != nil {
+		if errors.Is(err, errFirstWordTooLong) {
+			return Result{Reason: Exhausted}, nil
+		}
+		return Result{}, err
+	}
+	a.insert()
+
+	score := ag.Objective.Score(a.board.Cells)
+	bestScore := math.Inf(-1)
+	if a.board.BestBoard != nil {
+		bestScore = ag.Objective.Score(a.board.BestBoard)
+	}
+	var best layout
+	if score > bestScore {
+		bestScore = score
+		a.board.SaveBestSolution()
+		best = a.copyLayout()
+	}
+
+	reason := Exhausted
+	wordsOnly := ag.Objective.wordsOnly()
+	for step := 0; step < ag.Steps; step++ {
+		if a.board.WordCount == len(entries) && wordsOnly {
+			break
+		}
+		if a.shouldStop() {
+			reason = a.stop
+			break
+		}
+
+		before := a.copyLayout()
+		a.move(ag.MaxRemove)
+		next := ag.Objective.Score(a.board.Cells)
+		temperature := ag.StartTemperature * math.Pow(ag.EndTemperature/ag.StartTemperature, float64(step)/float64(ag.Steps))
+		if next < score && rng.Float64() >= math.Exp((next-score)/temperature) {
+			a.restore(before)
+			continue
+		}
+		score = next
+		if score > bestScore {
+			bestScore = score
+			a.board.SaveBestSolution()
+			best = a.copyLayout()
+		}
+	}
+
+	if a.board.BestWordCount == len(entries) && best.placed != nil {
+		a.restore(best)
+		return ag.result(a.board, Complete), nil
+	}
+	clearBoard(a.board)
+	return ag.result(a.board, reason), nil
+}
+
+// startLayout sets up the layout the search starts from: the words on the
+// board, or else the best solution of the board, or else the first word
+// across the middle of the board.
+func (a *annealer) startLayout() error {
+	placed, onBoard := a.board.PlacedWords, true
+	if len(placed) == 0 {
+		placed, onBoard = a.board.BestPlacedWords, false
+	}
+	for _, p := range placed {
+		i := a.entryOf(p)
+		if i < 0 {
+			if onBoard {
+				return fmt.Errorf("word %q on the board is not in the pool", p.Word)
+			}
+			continue
+		}
+		at := Placement{Start: p.Start, Direction: p.Direction}
+		if !onBoard {
+			if err := a.board.PlaceEntryAt(at.Start, a.entries[i], at.Direction); err != nil {
+				return err
+			}
+		}
+		a.placed[i], a.at[i] = true, at
+	}
+	if a.board.WordCount > 0 {
+		return nil
+	}
+
+	if err := placeFirstWord(a.board, a.entries[0]); err != nil {
+		return err
+	}
+	a.placed[0] = true
+	a.at[0] = Placement{Start: a.board.PlacedWords[0].Start, Direction: board.Across}
+	a.nodes++
+	return nil
+}
+
+// entryOf returns the index of the entry off the layout that the placed
+// word is, preferring one with the same hint, or -1 if there is none.
+func (a *annealer) entryOf(p board.PlacedWord) int {
+	found := -1
+	for i, entry := range a.entries {
+		if a.placed[i] || entry.Word != p.Word {
+			continue
+		}
+		if entry.Hint == p.Hint {
+			return i
+		}
+		if found < 0 {
+			found = i
+		}
+	}
+	return found
+}
+
+// move removes up to n random words, keeping at least one, and the words no
+// longer connected to the largest group of words, then inserts the words
+// off the board again.
+func (a *annealer) move(n int) {
+	var on []int
+	for i, placed := range a.placed {
+		if placed {
+			on = append(on, i)
+		}
+	}
+	a.rng.Shuffle(len(on), func(i, j int) { on[i], on[j] = on[j], on[i] })
+	remove := 1 + a.rng.Intn(max(n, 1))
+	for _, i := range on[:min(remove, len(on)-1)] {
+		a.remove(i)
+	}
+	a.removeIslands()
+	a.insert()
+}
+
+// insert places the words off the board in random order, each where it
+// crosses the most letters.
+func (a *annealer) insert() {
+	order := a.rng.Perm(len(a.entries))
+	for _, i := range order {
+		if a.placed[i] {
+			continue
+		}
+		placements := crossingPlacements(a.board, a.entries[i].Word)
+		if len(placements) == 0 {
+			continue
+		}
+		a.rng.Shuffle(len(placements), func(i, j int) { placements[i], placements[j] = placements[j], placements[i] })
+		best, most := placements[0], -1
+		for _, p := range placements {
+			if n := crossings(a.board, p, a.entries[i].Length()); n > most {
+				best, most = p, n
+			}
+		}
+		a.place(i, best)
+	}
+}
+
+// removeIslands removes the words not connected to the largest group of
+// words crossing each other.
+func (a *annealer) removeIslands() {
+	owner := make(map[board.Location][]int) // The words on each cell.
+	for i, placed := range a.placed {
+		if placed {
+			for _, l := range cellsOf(a.at[i], a.entries[i].Length()) {
+				owner[l] = append(owner[l], i)
+			}
+		}
+	}
+	group := make([]int, len(a.entries)) // The group of each word, 0 if none yet.
+	sizes := []int{0}
+	for i, placed := range a.placed {
+		if !placed || group[i] > 0 {
+			continue
+		}
+		g := len(sizes)
+		sizes = append(sizes, 0)
+		group[i] = g
+		queue := []int{i}
+		for len(queue) > 0 {
+			w := queue[0]
+			queue = queue[1:]
+			sizes[g]++
+			for _, l := range cellsOf(a.at[w], a.entries[w].Length()) {
+				for _, other := range owner[l] {
+					if group[other] == 0 {
+						group[other] = g
+						queue = append(queue, other)
+					}
+				}
+			}
+		}
+	}
+	largest := 0
+	for g, size := range sizes {
+		if size > sizes[largest] {
+			largest = g
+		}
+	}
+	for i, placed := range a.placed {
+		if placed && group[i] != largest {
+			a.remove(i)
+		}
+	}
+}
+
+// place puts the word at index i on the board.
+func (a *annealer) place(i int, p Placement) {
+	a.board.PlaceEntryAt(p.Start, a.entries[i], p.Direction)
+	a.placed[i], a.at[i] = true, p
+	a.nodes++
+}
+
+// remove takes the word at index i off the board.
+func (a *annealer) remove(i int) {
+	a.board.RemoveWord(a.at[i].Start, a.entries[i].Word, a.at[i].Direction)
+	a.placed[i] = false
+	a.backtracks++
+}
+
+// copyLayout returns a copy of the current layout.
+func (a *annealer) copyLayout() layout {
+	return layout{
+		placed: append([]bool(nil), a.placed...),
+		at:     append([]Placement(nil), a.at...),
+	}
+}
+
+// restore brings the board back to the layout l. Removing and placing words
+// only changes counts on the cells, so the order of the words does not
+// matter.
+func (a *annealer) restore(l layout) {
+	for i, placed := range a.placed {
+		if placed && (!l.placed[i] || a.at[i] != l.at[i]) {
+			a.board.RemoveWord(a.at[i].Start, a.entries[i].Word, a.at[i].Direction)
+			a.placed[i] = false
+		}
+	}
+	for i, placed := range l.placed {
+		if placed && !a.placed[i] {
+			a.board.PlaceEntryAt(l.at[i].Start, a.entries[i], l.at[i].Direction)
+			a.placed[i], a.at[i] = true, l.at[i]
+		}
+	}
+}
+
+// crossingPlacements returns the locations where the word can be placed on
+// the board. As a word has to cross a letter on the board, only the
+// locations that put one of its letters on a matching letter are checked,
+// which is faster than findPlacements on a large board.
+func crossingPlacements(b *board.Board, word string) []Placement {
+	runes := []rune(word)
+	seen := make(map[Placement]bool)
+	var placements []Placement
+	for y, row := range b.Cells {
+		for x, cell := range row {
+			if !cell.Filled {
+				continue
+			}
+			for i, r := range runes {
+				if cell.Character != string(r) {
+					continue
+				}
+				for _, dir := range []board.Direction{board.Across, board.Down} {
+					dx, dy := deltas(dir)
+					p := Placement{Start: board.Location{X: x - i*dx, Y: y - i*dy}, Direction: dir}
+					if p.Start.X < 0 || p.Start.Y < 0 || seen[p] {
+						continue
+					}
+					seen[p] = true
+					if b.CanPlaceWordAt(p.Start, word, dir) {
+						placements = append(placements, p)
+					}
+				}
+			}
+		}
+	}
+	return placements
+}
+
+// crossings returns the number of letters on the board a word of n letters
+// at p would cross.
+func crossings(b *board.Board, p Placement, n int) int {
+	count := 0
+	for _, l := range cellsOf(p, n) {
+		if b.Cells[l.Y][l.X].Filled {
+			count++
+		}
+	}
+	return count
+}
+
+// cellsOf returns the cells of a word of n letters at p.
+func cellsOf(p Placement, n int) []board.Location {
+	dx, dy := deltas(p.Direction)
+	cells := make([]board.Location, n)
+	for i := range cells {
+		cells[i] = board.Location{X: p.Start.X + i*dx, Y: p.Start.Y + i*dy}
+	}
+	return cells
+}
@@ -0,0 +1,93 @@
+package generators_test
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/generators"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+)
+
+// crowded lists words that do not all fit on an 11 by 11 board within the
+// budget of generateCrowded.
+var crowded = []string{"speisekarte", "einladung", "apfelsaft", "schinken", "kellner", "hunger", "lampe", "tisch", "stuhl", "haus", "glas", "brot"}
+
+// generateCrowded runs the named generator on the crowded words on an 11 by
+// 11 board.
+func generateCrowded(t *testing.T, name string) (*board.Board, generators.Result) {
+	t.Helper()
+	bounds, err := board.NewBoundsRectangle(11, 11)
+	if err != nil {
+		t.Fatalf("Failed to create bounds: %s", err)
+	}
+	b := board.NewBoard(bounds, len(crowded), &board.OSFileWriter{})
+	pool := words.NewPool()
+	pool.LoadWords(crowded)
+	g, err := generators.New(name, b, pool, generators.Options{
+		Rand:   rand.New(rand.NewSource(1)),
+		Budget: generators.Budget{MaxBacktracks: 2000},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	res, err := g.Generate(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	return b, res
+}
+
+// TestAnnealingGenerator_CrowdedBoard checks that local search places more
+// words than backtracking when not all words fit in the budget.
+func TestAnnealingGenerator_CrowdedBoard(t *testing.T) {
+	_, backtracking := generateCrowded(t, generators.Asymmetrical)
+	b, annealing := generateCrowded(t, generators.Annealing)
+	if annealing.Placed <= backtracking.Placed {
+		t.Errorf("Expected more words than backtracking, got: %d, backtracking: %d", annealing.Placed, backtracking.Placed)
+	}
+	checkLayout(t, b)
+}
+
+// TestAnnealingGenerator_StartLayout checks that the search starts from the
+// words on the board, and else from the best solution of the board, rather
+// than from the first word across the middle.
+func TestAnnealingGenerator_StartLayout(t *testing.T) {
+	list := []string{"haus", "see"}
+	haus := board.PlacedWord{Start: board.Location{X: 0, Y: 0}, Direction: board.Across, Word: "haus"}
+	for _, onBoard := range []bool{true, false} {
+		bounds, err := board.NewBoundsRectangle(7, 7)
+		if err != nil {
+			t.Fatalf("Failed to create bounds: %s", err)
+		}
+		b := board.NewBoard(bounds, len(list), &board.OSFileWriter{})
+		b.PlaceWordAt(haus.Start, haus.Word, haus.Direction)
+		if !onBoard {
+			b.SaveBestSolution()
+			b.RemoveWord(haus.Start, haus.Word, haus.Direction)
+		}
+		pool := words.NewPool()
+		pool.LoadWords(list)
+		g := generators.NewAnnealingGenerator(b, pool, rand.New(rand.NewSource(1)))
+		g.Steps = 0 // Only the greedy insertion, which keeps haus in place.
+
+		res, err := g.Generate(context.Background())
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if res.Reason != generators.Complete {
+			t.Fatalf("Incorrect reason, got: %s, want: %s", res.Reason, generators.Complete)
+		}
+		if got := b.BestPlacedWords[0]; got.Start != haus.Start || got.Direction != haus.Direction || got.Word != haus.Word {
+			t.Errorf("Incorrect first word with the layout on the board: %v, got: %+v, want: %+v", onBoard, got, haus)
+		}
+		checkLayout(t, b)
+	}
+}
+
+// TestAnnealingGenerator_Seeds checks that moves never put a word over
+// different letters.
+func TestAnnealingGenerator_Seeds(t *testing.T) {
+	checkSeeds(t, generators.Annealing)
+}
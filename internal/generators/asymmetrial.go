@@ -1,92 +1,166 @@
 package generators
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math/rand"
+	"sort"
 
-	"github.com/Germanicus1/crizzcrozz/pkg/models"
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
 )
 
-var backtrackCount int = 0  // Global counter for backtracking
-const maxBacktracks = 50000 // Limit for stopping excessive backtracking
-
 // AsymmetricalGenerator generates crossword puzzles without any
 // symmetry considerations.
 type AsymmetricalGenerator struct {
 	*BaseGenerator // to reuse common fields and methods.
-	WordPool       *models.Pool
+	WordPool       *words.Pool
+	// Rand shuffles the words of equal length and the placements of each
+	// word before they are tried, and picks the first word among the longest
+	// ones. Without it, words are tried in pool order and placements row by
+	// row.
+	Rand   *rand.Rand
+	Budget Budget // Limits the work of each call to Generate.
+	// Symmetry is the layout the generator aims for. It places words in
+	// mirrored pairs where it can, but places all words even if the layout
+	// ends up less symmetric; SymmetryScore tells how symmetric it is.
+	Symmetry Symmetry
+	// Pinned words are placed first, where they are pinned, instead of the
+	// longest word across the middle. The other words are placed around
+	// them, and backtracking never moves them. Pinned words that do not
+	// cross each other stay apart unless other words link them.
+	Pinned []board.PlacedWord
+	// Objective decides between boards with as many words which one is
+	// saved as the best solution.
+	Objective Objective
+
+	run                   // The state of the current call to Generate.
+	entries []words.Entry // The words left to place after the first or pinned ones.
 }
 
-func NewAsymmetricalGenerator(board *models.Board, pool *models.Pool) *AsymmetricalGenerator {
+func NewAsymmetricalGenerator(board *board.Board, pool *words.Pool, rng *rand.Rand) *AsymmetricalGenerator {
 	return &AsymmetricalGenerator{
 		BaseGenerator: NewBaseGenerator(board),
 		WordPool:      pool,
+		Rand:          rng,
+		Objective:     DefaultObjective,
 	}
 }
 
 func (ag *AsymmetricalGenerator) placeFirstWord() error {
-	if ag.Board == nil || len(ag.WordPool.Words) == 0 {
+	if ag.Board == nil || len(ag.WordPool.Entries) == 0 {
 		return fmt.Errorf("uninitialized board or pool, or empty words list")
 	}
+	if len(ag.Pinned) > 0 {
+		rest, err := placePinned(ag.Board, ag.WordPool.Entries, ag.Pinned)
+		if err != nil {
+			clearBoard(ag.Board)
+			return err
+		}
+		ag.entries = rest
+		return nil
+	}
 
 	// Place the first word at the center horizontally.
-	firstWord := ag.WordPool.Words[0]
-	midRow := ag.Board.Bounds.Height() / 2
-	startCol := (ag.Board.Bounds.Width() - len([]rune(firstWord))) / 2
-
-	err := ag.Board.PlaceWordAt(models.Location{X: startCol, Y: midRow}, firstWord, models.Across)
-	if err != nil {
-		return errors.New("failed to place the first word")
-	}
-	return nil
+	ag.entries = ag.WordPool.Entries[1:]
+	return placeFirstWord(ag.Board, ag.WordPool.Entries[0])
 }
 
-func (ag *AsymmetricalGenerator) Generate() error {
-	backtrackCount = 0 // Reset counter before recursion starts
+// Generate places the words of the pool on the board. It stops early when
+// ctx is done or the budget runs out. The best board found is saved as the
+// best solution of the board, and the board is left empty unless all words
+// were placed, so Generate can be called again for another attempt.
+func (ag *AsymmetricalGenerator) Generate(ctx context.Context) (Result, error) {
+	ag.start(ctx, ag.Budget) // Reset counters before recursion starts
+	if err := ag.Symmetry.checkBoard(ag.Board); err != nil {
+		return Result{}, err
+	}
+	if ag.Rand != nil {
+		shuffleTies(ag.Rand, ag.WordPool.Entries)
+		if len(ag.Pinned) == 0 {
+			pickFirstWord(ag.Rand, ag.WordPool.Entries)
+		}
+	}
 
 	// REM fmt.Println("Starting crossword generation...")
 
 	err := ag.placeFirstWord()
+	if errors.Is(err, errFirstWordTooLong) {
+		return Result{Reason: Exhausted}, nil
+	}
 	if err != nil {
-		fmt.Println("Error placing first word:", err)
-		return err
+		debugf("Error placing first word: %v\n", err)
+		return Result{}, err
+	}
+	ag.nodes++
+	saveIfBetter(ag.Board, ag.Objective)
+
+	err = ag.placeWordsRecursive(0)
+	if err == nil {
+		return ag.result(ag.Board, Complete), nil
+	}
+
+	// Clear the board of the first or pinned words for the next attempt.
+	clearBoard(ag.Board)
+	if ag.complete {
+		restoreBest(ag.Board)
+		return ag.result(ag.Board, Complete), nil
 	}
 
-	err = ag.placeWordsRecursive(1) // Start from the second word
 	// fmt.Println("\nBacktracking limit reached or crossword generation failed.")
-	return err
+	if errors.Is(err, errStopped) {
+		return ag.result(ag.Board, ag.stop), nil
+	}
+	return ag.result(ag.Board, Exhausted), nil
 }
 
 // REM debugging
 func (ag *AsymmetricalGenerator) placeWordsRecursive(index int) error {
-	if index >= len(ag.WordPool.Words) {
-		fmt.Println("\n✅ All words placed successfully! Saving best solution...")
-		ag.Board.SaveBestSolution()
-		return nil
+	if index >= len(ag.entries) {
+		debugf("\n✅ All words placed successfully! Saving best solution...\n")
+		saveIfBetter(ag.Board, ag.Objective)
+		if ag.found(ag.Objective) {
+			return nil
+		}
+		return errKeepLooking
 	}
 
-	word := ag.WordPool.Words[index]
-	fmt.Printf("\n🔍 Trying to place word #%d: %s\n", index+1, word)
+	entry := ag.entries[index]
+	word := entry.Word
+	debugf("\n🔍 Trying to place word #%d: %s\n", index+1, word)
 
 	placements := ag.FindPlacementLocations(word)
-	fmt.Printf("➡️ Available placements for %s: %d\n", word, len(placements))
+	if ag.Rand != nil {
+		ag.Rand.Shuffle(len(placements), func(i, j int) { placements[i], placements[j] = placements[j], placements[i] })
+	}
+	if !ag.Symmetry.none() {
+		ag.orderBySymmetry(placements, index)
+	}
+	debugf("➡️ Available placements for %s: %d\n", word, len(placements))
 
 	if len(placements) == 0 {
-		fmt.Printf("❌ No placements found for: %s\n", word)
+		debugf("❌ No placements found for: %s\n", word)
 		return fmt.Errorf("no placements available for word: %s", word)
 	}
 
 	for _, location := range placements {
+		if ag.shouldStop() {
+			return errStopped
+		}
+
 		// 🚨 Check if the placement is valid before proceeding
 		if !ag.Board.CanPlaceWordAt(location.Start, word, location.Direction) {
-			fmt.Printf("⚠️ Skipping invalid placement: %s at (%d, %d) %v (Would overwrite another word)\n",
+			debugf("⚠️ Skipping invalid placement: %s at (%d, %d) %v (Would overwrite another word)\n",
 				word, location.Start.X, location.Start.Y, location.Direction)
 			continue
 		}
 
-		if err := ag.Board.PlaceWordAt(location.Start, word, location.Direction); err == nil {
-			fmt.Printf("✅ Successfully placed word: %s at (%d, %d) %v\n",
+		if err := ag.Board.PlaceEntryAt(location.Start, entry, location.Direction); err == nil {
+			ag.nodes++
+			debugf("✅ Successfully placed word: %s at (%d, %d) %v\n",
 				word, location.Start.X, location.Start.Y, location.Direction)
+			saveIfBetter(ag.Board, ag.Objective)
 
 			err := ag.placeWordsRecursive(index + 1)
 			if err == nil {
@@ -95,12 +169,19 @@ func (ag *AsymmetricalGenerator) placeWordsRecursive(index int) error {
 
 			// Backtrack: remove the word and try the next placement
 			ag.Board.RemoveWord(location.Start, word, location.Direction)
-			fmt.Printf("🔄 Backtracking: Removed word %s from (%d, %d) %v\n",
+			if errors.Is(err, errStopped) {
+				return err
+			}
+			ag.backtracks++
+			if ag.shouldStop() {
+				return errStopped
+			}
+			debugf("🔄 Backtracking: Removed word %s from (%d, %d) %v\n",
 				word, location.Start.X, location.Start.Y, location.Direction)
 		}
 	}
 
-	fmt.Printf("⚠️ Failed to place word: %s\n", word)
+	debugf("⚠️ Failed to place word: %s\n", word)
 	return fmt.Errorf("failed to place word: %s", word)
 }
 
@@ -145,32 +226,51 @@ func (ag *AsymmetricalGenerator) placeWordsRecursive(index int) error {
 // 	return fmt.Errorf("failed to place word: %s", word)
 // }
 
-type Placement struct {
-	Start     models.Location
-	Direction models.Direction
+// orderBySymmetry moves the placements of the word at index that keep the
+// layout symmetric to the front, keeping their order otherwise. Placements
+// go first the more of their letters land on a mirror of a letter, so words
+// that mirror onto themselves or complete a pair with a placed word come
+// first. On ties, placements whose mirror a later word of the same length
+// can take, to make a pair, go first.
+func (ag *AsymmetricalGenerator) orderBySymmetry(placements []Placement, index int) {
+	w, h := ag.Board.Bounds.Width(), ag.Board.Bounds.Height()
+	n := ag.entries[index].Length()
+
+	rank := func(p Placement) int {
+		dx, dy := deltas(p.Direction)
+		own := make(map[board.Location]bool, n)
+		for i := 0; i < n; i++ {
+			own[board.Location{X: p.Start.X + i*dx, Y: p.Start.Y + i*dy}] = true
+		}
+		mirrored := 0
+		for l := range own {
+			x, y := ag.Symmetry.mirrorCell(w, h, l.X, l.Y)
+			if own[board.Location{X: x, Y: y}] || ag.Board.Cells[y][x].Filled {
+				mirrored++
+			}
+		}
+		rank := -2 * mirrored
+		if mirrored < n {
+			m := ag.Symmetry.mirror(w, h, p, n)
+			for _, entry := range ag.entries[index+1:] {
+				if entry.Length() == n && ag.Board.CanPlaceWordAt(m.Start, entry.Word, m.Direction) {
+					rank--
+					break
+				}
+			}
+		}
+		return rank
+	}
+	ranks := make(map[Placement]int, len(placements))
+	for _, p := range placements {
+		ranks[p] = rank(p)
+	}
+	sort.SliceStable(placements, func(i, j int) bool {
+		return ranks[placements[i]] < ranks[placements[j]]
+	})
 }
 
 // FindPlacementLocations generates a list of possible placement locations for a word.
 func (ag *AsymmetricalGenerator) FindPlacementLocations(word string) []Placement {
-	var placements []Placement
-
-	// Helper function to try placing a word in one direction
-	tryPlaceWord := func(x, y int, dir models.Direction) {
-		if ag.Board.CanPlaceWordAt(models.Location{X: x, Y: y}, word, dir) {
-			placements = append(placements, Placement{
-				Start:     models.Location{X: x, Y: y},
-				Direction: dir,
-			})
-		}
-		// fmt.Printf("%v at x: %v, y: %v going %v\n", word, x, y, directionString(dir))
-	}
-
-	// Iterate over each cell in the board
-	for y := 0; y < len(ag.Board.Cells); y++ {
-		for x := 0; x < len(ag.Board.Cells[y]); x++ {
-			tryPlaceWord(x, y, models.Across) // Try horizontal placement
-			tryPlaceWord(x, y, models.Down)   // Try vertical placement
-		}
-	}
-	return placements
+	return findPlacements(ag.Board, word)
 }
@@ -0,0 +1,211 @@
+package generators_test
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/generators"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+)
+
+// TestAsymmetricalGenerator_Concurrent runs generators on clones of one
+// board at the same time. Run it with -race to check they share no state.
+func TestAsymmetricalGenerator_Concurrent(t *testing.T) {
+	list := []string{"speisekarte", "einladung", "apfelsaft", "schinken", "kellner", "hunger", "lampe", "haus"}
+	bounds, err := board.NewBoundsRectangle(13, 13)
+	if err != nil {
+		t.Fatalf("Failed to create bounds: %s", err)
+	}
+	base := board.NewBoard(bounds, len(list), &board.OSFileWriter{})
+
+	generate := func(seed int64) generatorRun {
+		b := base.Clone()
+		pool := words.NewPool()
+		pool.LoadWords(list)
+		g, err := generators.New(generators.Asymmetrical, b, pool, generators.Options{
+			Rand:   rand.New(rand.NewSource(seed)),
+			Budget: generators.Budget{MaxBacktracks: 200},
+		})
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			return generatorRun{}
+		}
+		res, err := g.Generate(context.Background())
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		return generatorRun{res, b.BestPlacedWords}
+	}
+
+	const runs = 8
+	want := make([]generatorRun, runs)
+	for i := range want {
+		want[i] = generate(int64(i + 1))
+	}
+
+	got := make([]generatorRun, runs)
+	var wg sync.WaitGroup
+	for i := range got {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			got[i] = generate(int64(i + 1))
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("Concurrent run %d differs from the sequential one, got: %+v, want: %+v", i, got[i], want[i])
+		}
+	}
+	if base.WordCount != 0 || base.BestBoard != nil {
+		t.Errorf("Expected the shared board to stay empty, got: %d words", base.WordCount)
+	}
+}
+
+// TestAsymmetricalGenerator_Seeds checks that backtracking never leaves a
+// word over different letters.
+func TestAsymmetricalGenerator_Seeds(t *testing.T) {
+	checkSeeds(t, generators.Asymmetrical)
+}
+
+type generatorRun struct {
+	Result generators.Result
+	Placed []board.PlacedWord
+}
+
+// TestAsymmetricalGenerator_Mask places the first word off the middle row
+// when the mask blocks it.
+func TestAsymmetricalGenerator_Mask(t *testing.T) {
+	b, g := newGenerator(t, generators.Asymmetrical, 7, []string{"haus", "eis", "see"}, generators.Budget{})
+	mask, err := board.ParseMask(strings.NewReader(".......\n.......\n.......\n#######\n.......\n.......\n.......\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := b.SetMask(mask); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	res, err := g.Generate(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if res.Reason != generators.Complete {
+		t.Fatalf("Incorrect reason, got: %s, want: %s", res.Reason, generators.Complete)
+	}
+	if got, want := b.BestPlacedWords[0].Start, (board.Location{X: 1, Y: 2}); got != want {
+		t.Errorf("Incorrect start of the first word, got: %v, want: %v", got, want)
+	}
+	for x := range b.BestBoard[3] {
+		if b.BestBoard[3][x].Filled {
+			t.Errorf("Expected no letter in the blocked row, got: %s at %d", b.BestBoard[3][x].Character, x)
+		}
+	}
+	checkLayout(t, b)
+}
+
+func TestAsymmetricalGenerator_Pinned(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    []string
+		pinned  []board.PlacedWord
+		wantErr bool
+	}{
+		{
+			name:   "down the side",
+			list:   []string{"haus", "see", "eis"},
+			pinned: []board.PlacedWord{{Start: board.Location{X: 0, Y: 1}, Direction: board.Down, Word: "haus", Hint: "Gebäude"}},
+		},
+		{
+			name: "two crossing",
+			list: []string{"haus", "see", "eis", "tee"},
+			pinned: []board.PlacedWord{
+				{Start: board.Location{X: 1, Y: 3}, Direction: board.Across, Word: "haus"},
+				{Start: board.Location{X: 4, Y: 3}, Direction: board.Down, Word: "see"},
+			},
+		},
+		{
+			name:    "off the board",
+			list:    []string{"haus", "see", "eis"},
+			pinned:  []board.PlacedWord{{Start: board.Location{X: 5, Y: 0}, Direction: board.Across, Word: "haus"}},
+			wantErr: true,
+		},
+		{
+			name: "letters disagree",
+			list: []string{"haus", "see", "eis"},
+			pinned: []board.PlacedWord{
+				{Start: board.Location{X: 0, Y: 1}, Direction: board.Across, Word: "haus"},
+				{Start: board.Location{X: 1, Y: 0}, Direction: board.Down, Word: "see"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bounds, err := board.NewBoundsRectangle(7, 7)
+			if err != nil {
+				t.Fatalf("Failed to create bounds: %s", err)
+			}
+			b := board.NewBoard(bounds, len(tt.list), &board.OSFileWriter{})
+			pool := words.NewPool()
+			pool.LoadWords(tt.list)
+			g, err := generators.New(generators.Asymmetrical, b, pool, generators.Options{
+				Rand:   rand.New(rand.NewSource(1)),
+				Pinned: tt.pinned,
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			res, err := g.Generate(context.Background())
+			if tt.wantErr {
+				if !errors.Is(err, generators.ErrInvalidPin) {
+					t.Errorf("Incorrect error, got: %v, want: %v", err, generators.ErrInvalidPin)
+				}
+				if b.WordCount != 0 {
+					t.Errorf("Expected an empty board after an invalid pin, got: %d words", b.WordCount)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if res.Reason != generators.Complete || res.Placed != len(tt.list) {
+				t.Fatalf("Incorrect result, got: %d words %s, want: %d words %s", res.Placed, res.Reason, len(tt.list), generators.Complete)
+			}
+			for i, want := range tt.pinned {
+				if got := b.BestPlacedWords[i]; got != want {
+					t.Errorf("Incorrect pinned word, got: %+v, want: %+v", got, want)
+				}
+			}
+			checkLayout(t, b)
+		})
+	}
+}
+
+// TestNew_Pinned checks that generators which cannot place pinned words
+// refuse them.
+func TestNew_Pinned(t *testing.T) {
+	pinned := []board.PlacedWord{{Start: board.Location{X: 0, Y: 0}, Direction: board.Across, Word: "haus"}}
+	for _, name := range generators.Names {
+		t.Run(name, func(t *testing.T) {
+			bounds, err := board.NewBoundsRectangle(7, 7)
+			if err != nil {
+				t.Fatalf("Failed to create bounds: %s", err)
+			}
+			pool := words.NewPool()
+			pool.LoadWords([]string{"haus"})
+			_, err = generators.New(name, board.NewBoard(bounds, 1, &board.OSFileWriter{}), pool, generators.Options{Pinned: pinned})
+			if wantErr := name != generators.Asymmetrical; errors.Is(err, generators.ErrInvalidPin) != wantErr {
+				t.Errorf("Incorrect error, got: %v, want error: %v", err, wantErr)
+			}
+		})
+	}
+}
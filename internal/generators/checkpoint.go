@@ -0,0 +1,262 @@
+package generators
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+)
+
+// The defaults of the CheckpointGenerator settings.
+const (
+	DefaultBranching    = 4  // Placements tried per word before backtracking.
+	DefaultCheckpointAt = 6  // Placements a word needs for a checkpoint.
+	DefaultPatience     = 20 // Dead ends without progress before a jump.
+)
+
+// CheckpointGenerator generates crossword puzzles with the checkpoint search
+// of "Practical Crossword Generation with Checkpoint Search" (see papers/).
+//
+// Instead of placing the words in a fixed order, it places the word with the
+// fewest placements next, and tries at most Branching of them. A word with
+// many placements marks a checkpoint: the board state from which the search
+// can still branch out widely. When the search keeps running into dead ends
+// without finding a better board, it does not unwind one word at a time but
+// restores the board of the most promising checkpoint and goes on with
+// placements not tried there yet.
+type CheckpointGenerator struct {
+	*BaseGenerator
+	WordPool *words.Pool
+	// Rand shuffles the words of equal length and the placements of each
+	// word, and picks the first word among the longest ones. Without it, the
+	// search runs in a fixed order.
+	Rand   *rand.Rand
+	Budget Budget // Limits the work of each call to Generate.
+	// Objective decides between boards with as many words which one is
+	// saved as the best solution.
+	Objective Objective
+
+	Branching    int // The max number of placements tried per word before backtracking.
+	CheckpointAt int // Words with at least this many placements mark a checkpoint.
+	Patience     int // The number of dead ends without a better board before jumping to a checkpoint.
+
+	run                       // The state of the current call to Generate.
+	stack   []*checkpointNode // The words placed after the first one.
+	used    []bool            // The entries on the board, by index in the pool.
+	stalled int               // Dead ends since the best board last improved.
+}
+
+// checkpointNode is a word of the search, with the placements found for it
+// when it was picked. The placements up to hi are the ones the search tries
+// at this node; the ones after hi are kept for a jump back.
+type checkpointNode struct {
+	entry      int
+	placements []Placement
+	hi         int
+	next       int  // The index of the next placement to try.
+	placed     bool // Whether placements[next-1] is on the board.
+}
+
+// NewCheckpointGenerator returns a checkpoint search generator with the
+// default settings.
+func NewCheckpointGenerator(board *board.Board, pool *words.Pool, rng *rand.Rand) *CheckpointGenerator {
+	return &CheckpointGenerator{
+		BaseGenerator: NewBaseGenerator(board),
+		WordPool:      pool,
+		Rand:          rng,
+		Objective:     DefaultObjective,
+		Branching:     DefaultBranching,
+		CheckpointAt:  DefaultCheckpointAt,
+		Patience:      DefaultPatience,
+	}
+}
+
+// Generate places the words of the pool on the board. It stops early when
+// ctx is done or the budget runs out. The best board found is saved as the
+// best solution of the board, and the board is left empty unless all words
+// were placed, so Generate can be called again for another attempt.
+func (cg *CheckpointGenerator) Generate(ctx context.Context) (Result, error) {
+	if cg.Board == nil || len(cg.WordPool.Entries) == 0 {
+		return Result{}, fmt.Errorf("uninitialized board or pool, or empty words list")
+	}
+	cg.start(ctx, cg.Budget)
+	cg.stack, cg.stalled = nil, 0
+	cg.used = make([]bool, len(cg.WordPool.Entries))
+	if cg.Rand != nil {
+		shuffleTies(cg.Rand, cg.WordPool.Entries)
+		pickFirstWord(cg.Rand, cg.WordPool.Entries)
+	}
+
+	err := placeFirstWord(cg.Board, cg.WordPool.Entries[0])
+	if errors.Is(err, errFirstWordTooLong) {
+		return Result{Reason: Exhausted}, nil
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	cg.used[0] = true
+	cg.nodes++
+	saveIfBetter(cg.Board, cg.Objective)
+
+	err = cg.search()
+	if err == nil {
+		return cg.result(cg.Board, Complete), nil
+	}
+	res := cg.result(cg.Board, Exhausted)
+	if errors.Is(err, errStopped) {
+		res.Reason = cg.stop
+	}
+
+	// Clear the board for the next attempt.
+	cg.unwind(0)
+	first := cg.Board.PlacedWords[0]
+	cg.Board.RemoveWord(first.Start, first.Word, first.Direction)
+	if cg.complete {
+		restoreBest(cg.Board)
+		res.Reason = Complete
+	}
+	return res, nil
+}
+
+// errSearchExhausted is returned by search when every node ran out of
+// placements.
+var errSearchExhausted = errors.New("no placements left")
+
+// search places the remaining words until all are on the board.
+func (cg *CheckpointGenerator) search() error {
+	if cg.push() && cg.found(cg.Objective) {
+		return nil
+	}
+	for len(cg.stack) > 0 {
+		if cg.shouldStop() {
+			return errStopped
+		}
+
+		n := cg.stack[len(cg.stack)-1]
+		if n.placed {
+			cg.takeBack(n)
+			if cg.shouldStop() {
+				return errStopped
+			}
+		}
+		if n.next < n.hi {
+			if cg.place(n) && cg.push() && cg.found(cg.Objective) {
+				return nil
+			}
+			continue
+		}
+
+		// Dead end: no placements left to try at this node.
+		cg.used[n.entry] = false
+		cg.stack = cg.stack[:len(cg.stack)-1]
+		cg.stalled++
+		if cg.stalled >= cg.Patience {
+			cg.jump()
+		}
+	}
+	return errSearchExhausted
+}
+
+// push adds a node for the remaining word with the fewest placements, and
+// reports whether all words are placed. Words without placements are only
+// picked if no other word has any, as they may cross a word placed later.
+func (cg *CheckpointGenerator) push() bool {
+	best := -1
+	var placements []Placement
+	for i, entry := range cg.WordPool.Entries {
+		if cg.used[i] {
+			continue
+		}
+		p := findPlacements(cg.Board, entry.Word)
+		if best < 0 || (len(p) > 0 && (len(placements) == 0 || len(p) < len(placements))) {
+			best, placements = i, p
+		}
+	}
+	if best < 0 {
+		saveIfBetter(cg.Board, cg.Objective)
+		return true
+	}
+
+	if cg.Rand != nil {
+		cg.Rand.Shuffle(len(placements), func(i, j int) { placements[i], placements[j] = placements[j], placements[i] })
+	}
+	n := &checkpointNode{entry: best, placements: placements}
+	n.hi = min(cg.Branching, len(placements))
+	cg.used[best] = true
+	cg.stack = append(cg.stack, n)
+	return false
+}
+
+// place puts the next placement of the node on the board, and reports
+// whether it succeeded.
+func (cg *CheckpointGenerator) place(n *checkpointNode) bool {
+	p := n.placements[n.next]
+	n.next++
+	if err := cg.Board.PlaceEntryAt(p.Start, cg.WordPool.Entries[n.entry], p.Direction); err != nil {
+		return false
+	}
+	n.placed = true
+	cg.nodes++
+	if saveIfBetter(cg.Board, cg.Objective) {
+		cg.stalled = 0
+	}
+	return true
+}
+
+// takeBack removes the current placement of the node from the board.
+func (cg *CheckpointGenerator) takeBack(n *checkpointNode) {
+	p := n.placements[n.next-1]
+	cg.Board.RemoveWord(p.Start, cg.WordPool.Entries[n.entry].Word, p.Direction)
+	n.placed = false
+	cg.backtracks++
+}
+
+// unwind takes back the nodes of the stack down to depth, so the board is
+// the one the node at depth was picked on.
+func (cg *CheckpointGenerator) unwind(depth int) {
+	for len(cg.stack) > depth {
+		n := cg.stack[len(cg.stack)-1]
+		if n.placed {
+			cg.takeBack(n)
+		}
+		cg.used[n.entry] = false
+		cg.stack = cg.stack[:len(cg.stack)-1]
+	}
+}
+
+// isCheckpoint reports whether the node marks a checkpoint with placements
+// left to try.
+func (cg *CheckpointGenerator) isCheckpoint(n *checkpointNode) bool {
+	return len(n.placements) >= cg.CheckpointAt && n.hi < len(n.placements)
+}
+
+// jump restores the board of the most promising checkpoint, the one with
+// the most placements left, and the deepest one of those. The checkpoint
+// then tries the next Branching of its placements. Without a checkpoint on
+// the stack, the search just goes on backtracking.
+func (cg *CheckpointGenerator) jump() {
+	depth := -1
+	for i, n := range cg.stack {
+		if !cg.isCheckpoint(n) {
+			continue
+		}
+		if depth < 0 || len(n.placements)-n.hi >= len(cg.stack[depth].placements)-cg.stack[depth].hi {
+			depth = i
+		}
+	}
+	if depth < 0 {
+		return
+	}
+
+	cg.unwind(depth + 1)
+	n := cg.stack[depth]
+	if n.placed {
+		cg.takeBack(n)
+	}
+	n.next = n.hi
+	n.hi = min(n.hi+cg.Branching, len(n.placements))
+	cg.stalled = 0
+}
@@ -0,0 +1,44 @@
+package generators_test
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/generators"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+)
+
+// TestCheckpointGenerator_Jumps makes the generator jump to a checkpoint at
+// every dead end, and checks it still finds a complete board.
+func TestCheckpointGenerator_Jumps(t *testing.T) {
+	list := []string{"speisekarte", "einladung", "apfelsaft", "schinken", "kellner", "hunger", "lampe", "haus"}
+	bounds, err := board.NewBoundsRectangle(13, 13)
+	if err != nil {
+		t.Fatalf("Failed to create bounds: %s", err)
+	}
+	for seed := int64(1); seed <= 5; seed++ {
+		b := board.NewBoard(bounds, len(list), &board.OSFileWriter{})
+		pool := words.NewPool()
+		pool.LoadWords(list)
+		g := generators.NewCheckpointGenerator(b, pool, rand.New(rand.NewSource(seed)))
+		g.Branching, g.CheckpointAt, g.Patience = 1, 2, 1
+		g.Budget = generators.Budget{MaxNodes: 10000}
+
+		res, err := g.Generate(context.Background())
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if res.Reason != generators.Complete || b.BestWordCount != len(list) {
+			t.Errorf("Seed %d: incorrect result, got: %s with %d words, want: complete with %d", seed, res.Reason, b.BestWordCount, len(list))
+		}
+		checkLayout(t, b)
+	}
+}
+
+// TestCheckpointGenerator_Seeds checks that the placements tried after a
+// jump never put a word over different letters.
+func TestCheckpointGenerator_Seeds(t *testing.T) {
+	checkSeeds(t, generators.Checkpoint)
+}
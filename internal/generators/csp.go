@@ -0,0 +1,416 @@
+package generators
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+)
+
+// CSPGenerator generates crossword puzzles as a constraint satisfaction
+// problem, following "Crossword Puzzles as a Constraint Problem" (see
+// papers/).
+//
+// On a freeform board the slots are not known in advance, so the encoding is
+// turned around: every word is a variable, and its domain holds the slots,
+// start and direction, it fits in. Two words constrain each other where
+// their slots meet: crossing slots must agree on the shared letter, and
+// slots may not overlap, touch end to end or run side by side.
+//
+// After each placement, forward checking removes the slots of the other
+// words that conflict with it, and arc consistency removes the slots that
+// conflict with every slot left to another word. The next word is the most
+// constrained one: the word with the fewest slots crossing the board, no
+// matter its length. A word without slots left is a dead end found before it
+// is reached. If none of the slots of the next word crossing the board leads
+// to a solution, the search goes on with the word left to cross a word placed
+// later, so no board is missed.
+type CSPGenerator struct {
+	*BaseGenerator
+	WordPool *words.Pool
+	// Rand shuffles the words of equal length and the slots of each word,
+	// and picks the first word among the longest ones. Without it, the
+	// search runs in a fixed order.
+	Rand   *rand.Rand
+	Budget Budget // Limits the work of each call to Generate.
+	// Objective decides between boards with as many words which one is
+	// saved as the best solution.
+	Objective Objective
+
+	run              // The state of the current call to Generate.
+	vars  []*cspVar  // The words after the first one.
+	trail []cspPrune // The slots removed from the domains, in order.
+}
+
+// cspVar is a word of the problem with its domain.
+type cspVar struct {
+	entry    words.Entry
+	runes    []rune
+	domain   []Placement
+	removed  []bool // Whether the slot of the same index was removed.
+	size     int    // The number of slots left.
+	assigned int    // The index of the slot of the word on the board, -1 if none.
+}
+
+// cspPrune records the removal of a slot, so it can be undone.
+type cspPrune struct {
+	v    *cspVar
+	slot int
+}
+
+// NewCSPGenerator returns a constraint satisfaction generator.
+func NewCSPGenerator(board *board.Board, pool *words.Pool, rng *rand.Rand) *CSPGenerator {
+	return &CSPGenerator{
+		BaseGenerator: NewBaseGenerator(board),
+		WordPool:      pool,
+		Rand:          rng,
+		Objective:     DefaultObjective,
+	}
+}
+
+// Generate places the words of the pool on the board. It stops early when
+// ctx is done or the budget runs out. The best board found is saved as the
+// best solution of the board, and the board is left empty unless all words
+// were placed, so Generate can be called again for another attempt.
+func (cg *CSPGenerator) Generate(ctx context.Context) (Result, error) {
+	if cg.Board == nil || len(cg.WordPool.Entries) == 0 {
+		return Result{}, fmt.Errorf("uninitialized board or pool, or empty words list")
+	}
+	cg.start(ctx, cg.Budget)
+	cg.trail = nil
+	if cg.Rand != nil {
+		shuffleTies(cg.Rand, cg.WordPool.Entries)
+		pickFirstWord(cg.Rand, cg.WordPool.Entries)
+	}
+
+	entries := cg.WordPool.Entries
+	err := placeFirstWord(cg.Board, entries[0])
+	if errors.Is(err, errFirstWordTooLong) {
+		return Result{Reason: Exhausted}, nil
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	cg.nodes++
+	saveIfBetter(cg.Board, cg.Objective)
+
+	first := cg.Board.PlacedWords[0]
+	cg.vars = make([]*cspVar, 0, len(entries)-1)
+	for _, entry := range entries[1:] {
+		cg.vars = append(cg.vars, cg.newVar(entry, []rune(first.Word), Placement{Start: first.Start, Direction: first.Direction}))
+	}
+
+	err = errSearchExhausted
+	if cg.propagate(cg.vars) {
+		err = cg.assign()
+	}
+	if err == nil {
+		return cg.result(cg.Board, Complete), nil
+	}
+
+	clearBoard(cg.Board) // Clear the board for the next attempt.
+	if cg.complete {
+		restoreBest(cg.Board)
+		return cg.result(cg.Board, Complete), nil
+	}
+	if errors.Is(err, errStopped) {
+		return cg.result(cg.Board, cg.stop), nil
+	}
+	return cg.result(cg.Board, Exhausted), nil
+}
+
+// newVar returns the variable of the entry, with every slot on the board
+// that does not conflict with the first word.
+func (cg *CSPGenerator) newVar(entry words.Entry, first []rune, at Placement) *cspVar {
+	v := &cspVar{entry: entry, runes: []rune(entry.Word), assigned: -1}
+	for y := 0; y < len(cg.Board.Cells); y++ {
+		for x := 0; x < len(cg.Board.Cells[y]); x++ {
+			for _, dir := range []board.Direction{board.Across, board.Down} {
+				p := Placement{Start: board.Location{X: x, Y: y}, Direction: dir}
+				if cg.Board.Fits(p.Start, entry.Word, dir) && compatible(v.runes, p, first, at) {
+					v.domain = append(v.domain, p)
+				}
+			}
+		}
+	}
+	if cg.Rand != nil {
+		cg.Rand.Shuffle(len(v.domain), func(i, j int) { v.domain[i], v.domain[j] = v.domain[j], v.domain[i] })
+	}
+	v.removed = make([]bool, len(v.domain))
+	v.size = len(v.domain)
+	return v
+}
+
+// assign places the remaining words, the most constrained one first.
+func (cg *CSPGenerator) assign() error {
+	v, slots := cg.mostConstrained()
+	if v == nil {
+		if cg.found(cg.Objective) {
+			return nil
+		}
+		return errKeepLooking
+	}
+	if len(slots) == 0 {
+		return errSearchExhausted
+	}
+
+	for _, slot := range slots {
+		if cg.shouldStop() {
+			return errStopped
+		}
+		p := v.domain[slot]
+		if err := cg.Board.PlaceEntryAt(p.Start, v.entry, p.Direction); err != nil {
+			continue
+		}
+		v.assigned = slot
+		cg.nodes++
+		saveIfBetter(cg.Board, cg.Objective)
+
+		mark := len(cg.trail)
+		if cg.forwardCheck(v) {
+			err := cg.assign()
+			if err == nil || errors.Is(err, errStopped) {
+				return err
+			}
+		}
+
+		// Backtrack: restore the domains and try the next slot.
+		cg.undo(mark)
+		cg.Board.RemoveWord(p.Start, v.entry.Word, p.Direction)
+		v.assigned = -1
+		cg.backtracks++
+		if cg.shouldStop() {
+			return errStopped
+		}
+	}
+
+	// No slot crossing the board leads to a solution, so the word has to
+	// cross one of the words placed later.
+	mark := len(cg.trail)
+	for _, slot := range slots {
+		cg.remove(v, slot)
+	}
+	if v.size > 0 && cg.propagate([]*cspVar{v}) {
+		err := cg.assign()
+		if err == nil || errors.Is(err, errStopped) {
+			return err
+		}
+	}
+	cg.undo(mark)
+	return errSearchExhausted
+}
+
+// mostConstrained returns the unassigned word with the fewest slots that
+// cross a word on the board, and those slots. Words whose slots do not
+// cross the board yet are only picked if no other word is left; nil means
+// all words are placed.
+func (cg *CSPGenerator) mostConstrained() (*cspVar, []int) {
+	var best *cspVar
+	var bestSlots []int
+	for _, v := range cg.vars {
+		if v.assigned >= 0 {
+			continue
+		}
+		var slots []int
+		for i, p := range v.domain {
+			if !v.removed[i] && cg.crossesBoard(v.runes, p) {
+				slots = append(slots, i)
+			}
+		}
+		if best == nil || (len(slots) > 0 && (len(bestSlots) == 0 || len(slots) < len(bestSlots) ||
+			(len(slots) == len(bestSlots) && v.size < best.size))) {
+			best, bestSlots = v, slots
+		}
+	}
+	return best, bestSlots
+}
+
+// crossesBoard reports whether a slot shares a cell with a word on the
+// board.
+func (cg *CSPGenerator) crossesBoard(runes []rune, p Placement) bool {
+	dx, dy := deltas(p.Direction)
+	for i := range runes {
+		if cg.Board.Cells[p.Start.Y+i*dy][p.Start.X+i*dx].Filled {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardCheck removes the slots that conflict with the slot of v from the
+// other words, then makes the domains arc consistent. It reports false if a
+// word is left without slots.
+func (cg *CSPGenerator) forwardCheck(v *cspVar) bool {
+	at := v.domain[v.assigned]
+	var changed []*cspVar
+	for _, u := range cg.vars {
+		if u.assigned >= 0 {
+			continue
+		}
+		size := u.size
+		for i, p := range u.domain {
+			if !u.removed[i] && !compatible(u.runes, p, v.runes, at) {
+				cg.remove(u, i)
+			}
+		}
+		if u.size == 0 {
+			return false
+		}
+		if u.size < size {
+			changed = append(changed, u)
+		}
+	}
+	return cg.propagate(changed)
+}
+
+// propagate makes the domains of the unassigned words arc consistent (AC-3),
+// starting with the arcs towards the words whose domains changed. It reports
+// false if a word is left without slots.
+func (cg *CSPGenerator) propagate(changed []*cspVar) bool {
+	type arc struct{ from, to *cspVar }
+	var queue []arc
+	for _, to := range changed {
+		for _, from := range cg.vars {
+			if from != to && from.assigned < 0 {
+				queue = append(queue, arc{from, to})
+			}
+		}
+	}
+
+	for len(queue) > 0 {
+		if cg.ctx.Err() != nil {
+			return true // The search stops at the next placement.
+		}
+		a := queue[0]
+		queue = queue[1:]
+		if !cg.revise(a.from, a.to) {
+			continue
+		}
+		if a.from.size == 0 {
+			return false
+		}
+		for _, from := range cg.vars {
+			if from != a.from && from != a.to && from.assigned < 0 {
+				queue = append(queue, arc{from, a.from})
+			}
+		}
+	}
+	return true
+}
+
+// revise removes the slots of from that conflict with every slot left to
+// to, and reports whether it removed any.
+func (cg *CSPGenerator) revise(from, to *cspVar) bool {
+	revised := false
+	for i, p := range from.domain {
+		if from.removed[i] {
+			continue
+		}
+		supported := false
+		for j, q := range to.domain {
+			if !to.removed[j] && compatible(from.runes, p, to.runes, q) {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			cg.remove(from, i)
+			revised = true
+		}
+	}
+	return revised
+}
+
+// remove takes a slot out of the domain of v.
+func (cg *CSPGenerator) remove(v *cspVar, slot int) {
+	v.removed[slot] = true
+	v.size--
+	cg.trail = append(cg.trail, cspPrune{v, slot})
+}
+
+// undo puts back the slots removed since the trail had the length mark.
+func (cg *CSPGenerator) undo(mark int) {
+	for len(cg.trail) > mark {
+		pr := cg.trail[len(cg.trail)-1]
+		pr.v.removed[pr.slot] = false
+		pr.v.size++
+		cg.trail = cg.trail[:len(cg.trail)-1]
+	}
+}
+
+// compatible reports whether two words can be placed in the slots p and q
+// at the same time: where the slots share a cell the letters match, and
+// otherwise no letter of one word lies next to the other word, except
+// diagonally.
+func compatible(a []rune, p Placement, b []rune, q Placement) bool {
+	// Slots more than one cell apart do not constrain each other.
+	ax1, ay1 := slotEnd(p, len(a))
+	bx1, by1 := slotEnd(q, len(b))
+	if ax1+1 < q.Start.X || bx1+1 < p.Start.X || ay1+1 < q.Start.Y || by1+1 < p.Start.Y {
+		return true
+	}
+
+	// Neither word may continue into the other.
+	dx, dy := deltas(p.Direction)
+	if slotIndex(q, len(b), p.Start.X-dx, p.Start.Y-dy) >= 0 || slotIndex(q, len(b), ax1+dx, ay1+dy) >= 0 {
+		return false
+	}
+	ex, ey := deltas(q.Direction)
+	if slotIndex(p, len(a), q.Start.X-ex, q.Start.Y-ey) >= 0 || slotIndex(p, len(a), bx1+ex, by1+ey) >= 0 {
+		return false
+	}
+
+	for i, r := range a {
+		x, y := p.Start.X+i*dx, p.Start.Y+i*dy
+		if j := slotIndex(q, len(b), x, y); j >= 0 {
+			if p.Direction == q.Direction || b[j] != r {
+				return false
+			}
+			continue
+		}
+		// A letter next to the other word, across its own direction, would
+		// form a word of two letters.
+		if slotIndex(q, len(b), x+dy, y+dx) >= 0 || slotIndex(q, len(b), x-dy, y-dx) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// slotEnd returns the cell of the last letter of a slot of length n.
+func slotEnd(p Placement, n int) (int, int) {
+	dx, dy := deltas(p.Direction)
+	return p.Start.X + (n-1)*dx, p.Start.Y + (n-1)*dy
+}
+
+// slotIndex returns the index of the letter of a slot of length n at x, y,
+// or -1 if the slot does not cover that cell.
+func slotIndex(p Placement, n, x, y int) int {
+	var i int
+	if p.Direction == board.Across {
+		if y != p.Start.Y {
+			return -1
+		}
+		i = x - p.Start.X
+	} else {
+		if x != p.Start.X {
+			return -1
+		}
+		i = y - p.Start.Y
+	}
+	if i < 0 || i >= n {
+		return -1
+	}
+	return i
+}
+
+// deltas returns the step from one letter to the next in a direction.
+func deltas(d board.Direction) (int, int) {
+	if d == board.Across {
+		return 1, 0
+	}
+	return 0, 1
+}
@@ -0,0 +1,146 @@
+package generators_test
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/generators"
+	"github.com/Germanicus1/crizzcrozz/internal/puzzle"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+)
+
+// TestCSPGenerator_ShortWords fills a board with many short words, which the
+// asymmetrical generator cannot place at all, and checks the words only meet
+// where they cross.
+func TestCSPGenerator_ShortWords(t *testing.T) {
+	list := []string{"tisch", "stuhl", "lampe", "boden", "decke", "bett", "sofa", "ofen", "herd", "bad", "topf", "glas"}
+	bounds, err := board.NewBoundsRectangle(13, 13)
+	if err != nil {
+		t.Fatalf("Failed to create bounds: %s", err)
+	}
+	b := board.NewBoard(bounds, len(list), &board.OSFileWriter{})
+	pool := words.NewPool()
+	pool.LoadWords(list)
+	g, err := generators.New(generators.CSP, b, pool, generators.Options{
+		Rand:   rand.New(rand.NewSource(1)),
+		Budget: generators.Budget{MaxBacktracks: 5000},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	res, err := g.Generate(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if res.Reason != generators.Complete {
+		t.Fatalf("Incorrect reason, got: %s with %d words, want: %s", res.Reason, res.Placed, generators.Complete)
+	}
+
+	checkLayout(t, b)
+}
+
+// TestCSPGenerator_Seeds checks that the slots of the words never put a
+// word over different letters.
+func TestCSPGenerator_Seeds(t *testing.T) {
+	checkSeeds(t, generators.CSP)
+}
+
+// checkLayout checks that the best solution of the board is a valid layout:
+// the words are connected, their letters agree where they cross, no other
+// run of letters forms a word, and it can be written as a .puz file.
+func checkLayout(t *testing.T, b *board.Board) {
+	t.Helper()
+	if b.BestWordCount == 0 {
+		return
+	}
+	if got := (generators.Objective{Words: 1}).Score(b.BestBoard); got != float64(b.BestWordCount) {
+		t.Errorf("Incorrect number of words in the grid, got: %v, want: %d", got, b.BestWordCount)
+	}
+	if !connected(b.BestPlacedWords) {
+		t.Errorf("Expected connected words, got: %+v", b.BestPlacedWords)
+	}
+	p, err := puzzle.FromBoard(b)
+	if err == nil {
+		err = p.WritePuz(io.Discard)
+	}
+	if err != nil {
+		t.Errorf("Unexpected error writing the puzzle: %s", err)
+	}
+
+	// Every filled cell belongs to one across and one down word at most, and
+	// every word is followed and preceded by an empty cell.
+	letters := 0
+	for _, p := range b.BestPlacedWords {
+		letters += len([]rune(p.Word))
+	}
+	filled, crossings := 0, len(b.BestPlacedWords)-1
+	for y := range b.BestBoard {
+		for x := range b.BestBoard[y] {
+			if b.BestBoard[y][x].Filled {
+				filled++
+			}
+		}
+	}
+	if filled > letters-crossings {
+		t.Errorf("Incorrect number of filled cells, got: %d, want at most: %d", filled, letters-crossings)
+	}
+	for _, p := range b.BestPlacedWords {
+		dx, dy := 1, 0
+		if p.Direction == board.Down {
+			dx, dy = 0, 1
+		}
+		for i, r := range []rune(p.Word) {
+			cell := b.BestBoard[p.Start.Y+i*dy][p.Start.X+i*dx]
+			if cell.Character != string(r) {
+				t.Errorf("Incorrect letter %d of %s, got: %q, want: %q", i, p.Word, cell.Character, string(r))
+			}
+		}
+		for _, end := range []board.Location{
+			{X: p.Start.X - dx, Y: p.Start.Y - dy},
+			{X: p.Start.X + len([]rune(p.Word))*dx, Y: p.Start.Y + len([]rune(p.Word))*dy},
+		} {
+			if end.X >= 0 && end.Y >= 0 && end.Y < len(b.BestBoard) && end.X < len(b.BestBoard[0]) && b.BestBoard[end.Y][end.X].Filled {
+				t.Errorf("Expected an empty cell next to %s at %v", p.Word, end)
+			}
+		}
+	}
+}
+
+// connected reports whether every word is linked to the first one through
+// words crossing each other.
+func connected(placed []board.PlacedWord) bool {
+	cells := make([]map[board.Location]bool, len(placed))
+	for i, p := range placed {
+		dx, dy := 1, 0
+		if p.Direction == board.Down {
+			dx, dy = 0, 1
+		}
+		cells[i] = make(map[board.Location]bool)
+		for j := range []rune(p.Word) {
+			cells[i][board.Location{X: p.Start.X + j*dx, Y: p.Start.Y + j*dy}] = true
+		}
+	}
+	reached := map[int]bool{0: true}
+	queue := []int{0}
+	for len(queue) > 0 {
+		w := queue[0]
+		queue = queue[1:]
+		for other := range placed {
+			if reached[other] {
+				continue
+			}
+			for l := range cells[w] {
+				if cells[other][l] {
+					reached[other] = true
+					queue = append(queue, other)
+					break
+				}
+			}
+		}
+	}
+	return len(reached) == len(placed)
+}
@@ -0,0 +1,256 @@
+package generators
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+)
+
+// The defaults of the DenseGenerator settings.
+const (
+	DefaultMinLength         = 3    // Letters of the shortest word.
+	DefaultMaxBlack          = 0.25 // Share of black squares.
+	DefaultPatterns          = 50   // Patterns tried per call to Generate.
+	DefaultPatternBacktracks = 2000 // Backtracks before a pattern is given up.
+)
+
+// DenseGenerator fills the whole board like a printed crossword: every
+// cell is either a black square or a letter of an across word, a down word
+// or both. The words are taken from the pool and the Dictionary; unlike the
+// other generators it does not place all words of the pool, but every word
+// at most once.
+//
+// The pattern of black squares and the fill are composed together. A
+// pattern is drawn with word lengths the dictionary has words for, then
+// filled with the hierarchical CSP of "Crossword Grid Composition with a
+// Hierarchical CSP Encoding" (see papers/). If the fill runs into a
+// deadlock, the pattern is given up for a new one. The cells the mask of
+// the board blocks are black squares of every pattern.
+//
+// A complete board is one without empty slots; its TotalWords is set to the
+// number of words in the grid.
+type DenseGenerator struct {
+	*BaseGenerator
+	WordPool *words.Pool
+	// Rand draws the patterns and shuffles the words tried for each slot.
+	// Without it, patterns are drawn from a fixed seed and the words are
+	// tried in pool order.
+	Rand   *rand.Rand
+	Budget Budget // Limits the work of each call to Generate.
+	// Dictionary holds more words to fill the grid with, besides those of
+	// the pool.
+	Dictionary []words.Entry
+	// Objective decides between partial grids with as many words which one
+	// is saved as the best solution.
+	Objective Objective
+
+	MinLength         int     // The min number of letters of a word.
+	MaxBlack          float64 // The max share of black squares, between 0 and 1.
+	Patterns          int     // The max number of patterns tried per call to Generate.
+	PatternBacktracks int     // The max number of backtracks per pattern; no limit if zero.
+
+	run // The state of the current call to Generate.
+}
+
+// NewDenseGenerator returns a dense grid generator with the default
+// settings.
+func NewDenseGenerator(board *board.Board, pool *words.Pool, rng *rand.Rand) *DenseGenerator {
+	return &DenseGenerator{
+		BaseGenerator:     NewBaseGenerator(board),
+		WordPool:          pool,
+		Rand:              rng,
+		Objective:         DefaultObjective,
+		MinLength:         DefaultMinLength,
+		MaxBlack:          DefaultMaxBlack,
+		Patterns:          DefaultPatterns,
+		PatternBacktracks: DefaultPatternBacktracks,
+	}
+}
+
+// Generate composes a dense grid from the words of the pool and the
+// dictionary. It stops early when ctx is done or the budget runs out. The
+// best grid found is saved as the best solution of the board, and the board
+// is left empty unless the grid is complete, so Generate can be called again
+// for another attempt.
+func (dg *DenseGenerator) Generate(ctx context.Context) (Result, error) {
+	if dg.Board == nil || len(dg.WordPool.Entries) == 0 {
+		return Result{}, fmt.Errorf("uninitialized board or pool, or empty words list")
+	}
+	dg.start(ctx, dg.Budget)
+	rng := dg.Rand
+	if rng == nil {
+		rng = rand.New(rand.NewSource(1))
+	}
+
+	entries := mergeEntries(dg.WordPool.Entries, dg.Dictionary)
+	empty := maskGrid(dg.Board, false)
+	masked := empty.blackCount()
+	rules := gridRules{
+		maxBlack:  masked + int(dg.MaxBlack*float64(len(empty.black)-masked)),
+		minLength: dg.MinLength,
+		lengths:   entryLengths(entries),
+	}
+	reason := dg.compose(composer{
+		board:             dg.Board,
+		entries:           entries,
+		rng:               dg.Rand,
+		objective:         dg.Objective,
+		patterns:          dg.Patterns,
+		patternBacktracks: dg.PatternBacktracks,
+		draw: func() (*grid, []fixedWord, bool) {
+			g, ok := randomGrid(rng, empty, nil, rules)
+			return g, nil, ok
+		},
+	})
+	return dg.result(dg.Board, reason), nil
+}
+
+// fixedWord is a word placed in a slot before the fill, like a theme word.
+type fixedWord struct {
+	Placement
+	entry int // The index of the word in the entries of the composer.
+}
+
+// composer holds what the dense generators need to compose a grid.
+type composer struct {
+	board             *board.Board
+	entries           []words.Entry
+	rng               *rand.Rand // Shuffles the words tried for each slot, if set.
+	objective         Objective  // Decides between partial grids with as many words.
+	patterns          int        // The max number of patterns tried.
+	patternBacktracks int        // The max number of backtracks per pattern.
+	// draw returns a new pattern with the words fixed in it, or false if it
+	// could not draw one.
+	draw func() (*grid, []fixedWord, bool)
+}
+
+// compose draws patterns and fills them, until a grid is complete, the
+// patterns run out or the run has to stop, and returns why it ended. The
+// best grid is saved as the best solution of the board, a partial one only
+// if its words form a crossword of their own, and the board is left empty
+// unless the grid is complete.
+func (r *run) compose(c composer) Reason {
+	var bestSlots []slot
+	var best []int
+	bestCount := 0
+	reason := Exhausted
+	for pattern := 0; pattern < c.patterns; pattern++ {
+		if r.shouldStop() {
+			reason = r.stop
+			break
+		}
+		g, fixed, ok := c.draw()
+		if !ok {
+			continue
+		}
+		slots := g.slots()
+		f, ok := newFiller(r, c.rng, c.entries, g, slots)
+		if !ok || !f.fixAll(fixed) {
+			continue
+		}
+		f.maxBacktracks = c.patternBacktracks
+
+		err := f.fill()
+		if f.bestCount > bestCount {
+			bestSlots, best, bestCount = slots, f.best, f.bestCount
+		}
+		if err == nil {
+			bestSlots, best = slots, f.assigned
+			reason = Complete
+			break
+		}
+		if errors.Is(err, errStopped) {
+			reason = r.stop
+			break
+		}
+	}
+
+	placeGrid(c.board, c.entries, bestSlots, best, reason == Complete, c.objective)
+	if reason != Complete {
+		clearBoard(c.board)
+	}
+	return reason
+}
+
+// placeGrid puts the assigned words of the slots on the board and saves the
+// board if it is complete, or if it is a crossword and the best one so far
+// by the objective.
+func placeGrid(b *board.Board, entries []words.Entry, slots []slot, assigned []int, complete bool, o Objective) {
+	if slots == nil {
+		return
+	}
+	for s, w := range assigned {
+		if w < 0 {
+			continue
+		}
+		b.PlaceEntryAt(slots[s].Start, entries[w], slots[s].Direction)
+	}
+	if complete {
+		b.SaveBestSolution()
+		b.TotalWords = len(slots)
+	} else if isCrossword(b) && saveIfBetter(b, o) {
+		b.TotalWords = len(slots)
+	}
+}
+
+// isCrossword reports whether the letters on the board form a crossword:
+// every run of two or more letters is a placed word, and the letters are
+// connected. The words of a partial fill often are not, as the letters of
+// the words across run into each other down the grid.
+func isCrossword(b *board.Board) bool {
+	if countWords(b.Cells) != b.WordCount {
+		return false
+	}
+	var queue []board.Location
+	filled := 0
+	for y, row := range b.Cells {
+		for x, cell := range row {
+			if cell.Filled {
+				filled++
+				if queue == nil {
+					queue = []board.Location{{X: x, Y: y}}
+				}
+			}
+		}
+	}
+	seen := make(map[board.Location]bool)
+	for len(queue) > 0 {
+		l := queue[0]
+		queue = queue[1:]
+		if l.Y < 0 || l.Y >= len(b.Cells) || l.X < 0 || l.X >= len(b.Cells[l.Y]) || !b.Cells[l.Y][l.X].Filled || seen[l] {
+			continue
+		}
+		seen[l] = true
+		queue = append(queue, board.Location{X: l.X - 1, Y: l.Y}, board.Location{X: l.X + 1, Y: l.Y}, board.Location{X: l.X, Y: l.Y - 1}, board.Location{X: l.X, Y: l.Y + 1})
+	}
+	return len(seen) == filled
+}
+
+// mergeEntries returns the entries of a followed by those of b, without
+// repeating a word.
+func mergeEntries(a, b []words.Entry) []words.Entry {
+	seen := make(map[string]bool, len(a)+len(b))
+	merged := make([]words.Entry, 0, len(a)+len(b))
+	for _, list := range [][]words.Entry{a, b} {
+		for _, entry := range list {
+			if !seen[entry.Word] {
+				seen[entry.Word] = true
+				merged = append(merged, entry)
+			}
+		}
+	}
+	return merged
+}
+
+// entryLengths returns the lengths there are entries of.
+func entryLengths(entries []words.Entry) map[int]bool {
+	lengths := make(map[int]bool)
+	for _, entry := range entries {
+		lengths[entry.Length()] = true
+	}
+	return lengths
+}
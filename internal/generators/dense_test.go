@@ -0,0 +1,131 @@
+package generators_test
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/generators"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+)
+
+// letterGrid returns the rows and columns of a grid of random letters, the
+// dictionary of a board without black squares.
+func letterGrid(rng *rand.Rand, size int) []string {
+	letters := make([][]rune, size)
+	for y := range letters {
+		letters[y] = make([]rune, size)
+		for x := range letters[y] {
+			letters[y][x] = rune('a' + rng.Intn(26))
+		}
+	}
+	var list []string
+	for i := 0; i < size; i++ {
+		row, col := make([]rune, size), make([]rune, size)
+		for j := 0; j < size; j++ {
+			row[j], col[j] = letters[i][j], letters[j][i]
+		}
+		list = append(list, string(row), string(col))
+	}
+	return list
+}
+
+func newDenseGenerator(t *testing.T, size int, list []string) (*board.Board, *generators.DenseGenerator) {
+	t.Helper()
+	bounds, err := board.NewBoundsRectangle(size, size)
+	if err != nil {
+		t.Fatalf("Failed to create bounds: %s", err)
+	}
+	b := board.NewBoard(bounds, len(list), &board.OSFileWriter{})
+	pool := words.NewPool()
+	pool.LoadWords(list)
+	return b, generators.NewDenseGenerator(b, pool, rand.New(rand.NewSource(1)))
+}
+
+// TestDenseGenerator_BlackSquares fills a board from a dictionary with
+// words of all lengths and checks that every white cell is part of a word
+// and every word is in the dictionary.
+func TestDenseGenerator_BlackSquares(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	var list []string
+	for size := 3; size <= 5; size++ {
+		for i := 0; i < 20; i++ {
+			list = append(list, letterGrid(rng, size)...)
+		}
+	}
+	for _, w := range []string{"aaa", "eee", "iii", "ooo", "uuu"} {
+		list = append(list, w)
+	}
+	b, g := newDenseGenerator(t, 5, list)
+	g.MaxBlack = 0.4
+	g.Budget = generators.Budget{MaxBacktracks: 20000}
+
+	res, err := g.Generate(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if res.Reason != generators.Complete {
+		t.Fatalf("Incorrect reason, got: %s, want: %s", res.Reason, generators.Complete)
+	}
+
+	dictionary := make(map[string]bool)
+	for _, w := range list {
+		dictionary[w] = true
+	}
+	inWord := make(map[board.Location]bool)
+	seen := make(map[string]bool)
+	for _, p := range b.BestPlacedWords {
+		if !dictionary[p.Word] || seen[p.Word] {
+			t.Errorf("Unexpected word %s", p.Word)
+		}
+		seen[p.Word] = true
+		dx, dy := 1, 0
+		if p.Direction == board.Down {
+			dx, dy = 0, 1
+		}
+		for i := range []rune(p.Word) {
+			inWord[board.Location{X: p.Start.X + i*dx, Y: p.Start.Y + i*dy}] = true
+		}
+	}
+	for y := range b.BestBoard {
+		for x, cell := range b.BestBoard[y] {
+			if cell.Filled != inWord[board.Location{X: x, Y: y}] {
+				t.Errorf("Incorrect cell at (%d, %d), filled: %t, in a word: %t", x, y, cell.Filled, inWord[board.Location{X: x, Y: y}])
+			}
+		}
+	}
+	checkLayout(t, b)
+}
+
+// TestDenseGenerator_Mask fills a 5 by 5 square in the middle of a board
+// whose mask blocks the cells around it.
+func TestDenseGenerator_Mask(t *testing.T) {
+	list := letterGrid(rand.New(rand.NewSource(5)), 5)
+	b, g := newDenseGenerator(t, 7, list)
+	mask, err := board.ParseMask(strings.NewReader("#######\n#.....#\n#.....#\n#.....#\n#.....#\n#.....#\n#######\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := b.SetMask(mask); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	g.MaxBlack = 0
+
+	res, err := g.Generate(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if res.Reason != generators.Complete {
+		t.Fatalf("Incorrect reason, got: %s, want: %s", res.Reason, generators.Complete)
+	}
+	for y, row := range b.BestBoard {
+		for x, cell := range row {
+			if cell.Filled != b.Allowed(x, y) {
+				t.Errorf("Incorrect cell (%d, %d), got filled: %v, want: %v", x, y, cell.Filled, b.Allowed(x, y))
+			}
+		}
+	}
+	checkLayout(t, b)
+}
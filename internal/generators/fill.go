@@ -0,0 +1,286 @@
+package generators
+
+import (
+	"errors"
+	"math/rand"
+
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+)
+
+// errDeadlock is returned by fill when the search of a pattern took too
+// many backtracks, so another pattern is more promising.
+var errDeadlock = errors.New("fill deadlocked")
+
+// filler fills the slots of a grid with distinct words of a dictionary. It
+// follows the hierarchical CSP of "Crossword Grid Composition with a
+// Hierarchical CSP Encoding" (see papers/): at the high level every slot is
+// a variable with the words of its length as domain, at the low level every
+// cell is a variable with the letters the words of its slots allow. The
+// search assigns whole words to slots, and the cells channel every choice to
+// the crossing slots, whose words must agree on the letter of the cell.
+type filler struct {
+	*run
+	rng           *rand.Rand
+	entries       []words.Entry
+	runes         [][]rune
+	slots         []slot
+	cellSlots     [][]slotCell // The slots of each cell, with the index of the cell in the slot.
+	domains       [][]int      // The indices of the words each slot may take.
+	assigned      []int        // The word of each slot, -1 if none.
+	used          []bool       // Whether a word is assigned to a slot.
+	trail         []domainChange
+	maxBacktracks int // The max number of backtracks before the fill gives up; no limit if zero.
+	backtracks    int
+	fixed         int // The number of slots fixed before the fill.
+
+	best      []int // The assignment with the most words so far.
+	bestCount int
+}
+
+type slotCell struct{ slot, index int }
+
+// domainChange records a domain before it changed, so it can be undone.
+type domainChange struct {
+	slot   int
+	domain []int
+}
+
+// newFiller returns a filler for the slots of the grid. It reports false if
+// a slot has no words to start with.
+func newFiller(r *run, rng *rand.Rand, entries []words.Entry, g *grid, slots []slot) (*filler, bool) {
+	f := &filler{
+		run:       r,
+		rng:       rng,
+		entries:   entries,
+		runes:     make([][]rune, len(entries)),
+		slots:     slots,
+		cellSlots: make([][]slotCell, len(g.black)),
+		domains:   make([][]int, len(slots)),
+		assigned:  make([]int, len(slots)),
+		used:      make([]bool, len(entries)),
+	}
+	byLength := make(map[int][]int)
+	for i, entry := range entries {
+		f.runes[i] = []rune(entry.Word)
+		byLength[len(f.runes[i])] = append(byLength[len(f.runes[i])], i)
+	}
+	for s, sl := range slots {
+		for i, c := range sl.cells {
+			f.cellSlots[c] = append(f.cellSlots[c], slotCell{s, i})
+		}
+		f.domains[s] = byLength[len(sl.cells)]
+		f.assigned[s] = -1
+		if len(f.domains[s]) == 0 {
+			return nil, false
+		}
+	}
+	return f, true
+}
+
+// fix assigns the word w to slot s before the fill, and reports false if
+// that leaves another slot without words.
+func (f *filler) fix(s, w int) bool {
+	if f.used[w] || !contains(f.domains[s], w) {
+		return false
+	}
+	f.assign(s, w)
+	f.fixed++
+	return f.exclude(s, w)
+}
+
+// fixAll fixes the words in their slots before the fill, and reports false
+// if a word has no such slot or the words leave a slot without words.
+func (f *filler) fixAll(fixed []fixedWord) bool {
+	for _, fw := range fixed {
+		s := -1
+		for t, sl := range f.slots {
+			if sl.Placement == fw.Placement && len(sl.cells) == len(f.runes[fw.entry]) {
+				s = t
+			}
+		}
+		if s < 0 || !f.fix(s, fw.entry) {
+			return false
+		}
+	}
+	return true
+}
+
+// fill assigns a word to every slot. It returns errSearchExhausted if the
+// slots cannot be filled, errDeadlock if the fill gave up, and errStopped
+// if the run has to stop.
+func (f *filler) fill() error {
+	all := make([]int, len(f.slots))
+	for s := range all {
+		all[s] = s
+	}
+	if !f.channel(all) {
+		return errSearchExhausted
+	}
+	return f.search(f.fixed)
+}
+
+// search assigns words to the open slots, the slot with the fewest words
+// left first. depth is the number of slots assigned so far.
+func (f *filler) search(depth int) error {
+	s := -1
+	for t := range f.slots {
+		if f.assigned[t] >= 0 {
+			continue
+		}
+		if s < 0 || len(f.domains[t]) < len(f.domains[s]) ||
+			(len(f.domains[t]) == len(f.domains[s]) && len(f.slots[t].cells) > len(f.slots[s].cells)) {
+			s = t
+		}
+	}
+	if s < 0 {
+		return nil
+	}
+
+	candidates := append([]int(nil), f.domains[s]...)
+	if f.rng != nil {
+		f.rng.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+	}
+	for _, w := range candidates {
+		if f.shouldStop() {
+			return errStopped
+		}
+		if f.used[w] {
+			continue
+		}
+
+		mark := len(f.trail)
+		f.assign(s, w)
+		if depth+1 > f.bestCount {
+			f.bestCount = depth + 1
+			f.best = append(f.best[:0], f.assigned...)
+		}
+		if f.exclude(s, w) {
+			err := f.search(depth + 1)
+			if err == nil || !errors.Is(err, errSearchExhausted) {
+				return err
+			}
+		}
+
+		// Backtrack: restore the domains and try the next word.
+		f.undo(mark)
+		f.assigned[s] = -1
+		f.used[w] = false
+		f.backtracks++
+		f.run.backtracks++
+		if f.shouldStop() {
+			return errStopped
+		}
+		if f.maxBacktracks > 0 && f.backtracks >= f.maxBacktracks {
+			return errDeadlock
+		}
+	}
+	return errSearchExhausted
+}
+
+// assign gives the word w to slot s.
+func (f *filler) assign(s, w int) {
+	f.setDomain(s, []int{w})
+	f.assigned[s] = w
+	f.used[w] = true
+	f.nodes++
+}
+
+// exclude removes the word w of slot s from the other slots, as no word
+// may appear twice, and channels the changes through the cells. It reports
+// false if a slot is left without words.
+func (f *filler) exclude(s, w int) bool {
+	changed := []int{s}
+	for t, domain := range f.domains {
+		if t == s || f.assigned[t] >= 0 || len(f.slots[t].cells) != len(f.slots[s].cells) {
+			continue
+		}
+		kept := filter(domain, func(v int) bool { return v != w })
+		if len(kept) < len(domain) {
+			if len(kept) == 0 {
+				return false
+			}
+			f.setDomain(t, kept)
+			changed = append(changed, t)
+		}
+	}
+	return f.channel(changed)
+}
+
+// channel makes the slots and cells consistent, starting with the slots
+// whose domains changed: a cell keeps the letters that the words of all its
+// slots allow, and a slot keeps the words whose letters all cells allow. It
+// reports false if a slot is left without words.
+func (f *filler) channel(queue []int) bool {
+	queued := make([]bool, len(f.slots))
+	for _, s := range queue {
+		queued[s] = true
+	}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		queued[s] = false
+
+		for i, c := range f.slots[s].cells {
+			letters := make(map[rune]bool)
+			for _, w := range f.domains[s] {
+				letters[f.runes[w][i]] = true
+			}
+			for _, sc := range f.cellSlots[c] {
+				if sc.slot == s {
+					continue
+				}
+				domain := f.domains[sc.slot]
+				kept := filter(domain, func(w int) bool { return letters[f.runes[w][sc.index]] })
+				if len(kept) == len(domain) {
+					continue
+				}
+				if len(kept) == 0 {
+					return false
+				}
+				f.setDomain(sc.slot, kept)
+				if !queued[sc.slot] {
+					queued[sc.slot] = true
+					queue = append(queue, sc.slot)
+				}
+			}
+		}
+	}
+	return true
+}
+
+// setDomain changes the domain of slot s, keeping the old one on the trail.
+func (f *filler) setDomain(s int, domain []int) {
+	f.trail = append(f.trail, domainChange{s, f.domains[s]})
+	f.domains[s] = domain
+}
+
+// undo restores the domains changed since the trail had the length mark.
+func (f *filler) undo(mark int) {
+	for len(f.trail) > mark {
+		change := f.trail[len(f.trail)-1]
+		f.domains[change.slot] = change.domain
+		f.trail = f.trail[:len(f.trail)-1]
+	}
+}
+
+// filter returns the values of domain that keep reports true for, in a new
+// slice.
+func filter(domain []int, keep func(int) bool) []int {
+	var kept []int
+	for _, v := range domain {
+		if keep(v) {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// contains reports whether v is in domain.
+func contains(domain []int, v int) bool {
+	for _, d := range domain {
+		if d == v {
+			return true
+		}
+	}
+	return false
+}
@@ -1,25 +1,159 @@
 package generators
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
 
-	"github.com/Germanicus1/crizzcrozz/pkg/models"
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
 )
 
-// Generator defines the interface for generating crossword puzzles.
+// The names of the generators. Asymmetrical is the default one.
+const (
+	Asymmetrical = "asymmetrical" // The AsymmetricalGenerator.
+	Checkpoint   = "checkpoint"   // The CheckpointGenerator.
+	CSP          = "csp"          // The CSPGenerator.
+	Dense        = "dense"        // The DenseGenerator.
+	American     = "american"     // The AmericanGenerator.
+	Annealing    = "annealing"    // The AnnealingGenerator.
+	Genetic      = "genetic"      // The GeneticGenerator.
+)
+
+// ErrUnknownGenerator is returned by New for names it does not know.
+var ErrUnknownGenerator = errors.New("unknown generator")
+
+// Names lists the names of the available generators.
+var Names = []string{Asymmetrical, Checkpoint, CSP, Dense, American, Annealing, Genetic}
+
+// Options holds the settings shared by all generators.
+type Options struct {
+	// Rand is the source of all random choices, so the same seed gives the
+	// same board. With a nil Rand generators search in a fixed order.
+	Rand   *rand.Rand
+	Budget Budget // Limits the work of each call to Generate.
+	// Dictionary holds the words the dense generators fill the grid with,
+	// besides those of the pool. The other generators ignore it.
+	Dictionary []words.Entry
+	// Symmetry is the layout the AsymmetricalGenerator aims for. New fails
+	// for the other generators, and for symmetries the board cannot follow.
+	Symmetry Symmetry
+	// Objective is what the AnnealingGenerator and the GeneticGenerator
+	// optimize, and what the other generators keep the best of the layouts
+	// with the most words by; DefaultObjective if zero.
+	Objective Objective
+	// Pinned words are placed by hand before the other words. Only the
+	// AsymmetricalGenerator places them; New fails for the other
+	// generators rather than leave them out.
+	Pinned []board.PlacedWord
+	// Workers is the number of goroutines the GeneticGenerator evaluates
+	// its genomes on; the number of CPUs if zero. The other generators run
+	// on the goroutine that calls Generate.
+	Workers int
+}
+
+// New returns the generator with the given name for the board and pool. An
+// empty name selects the default generator.
+func New(name string, b *board.Board, pool *words.Pool, opts Options) (Generator, error) {
+	if err := opts.Symmetry.check(); err != nil {
+		return nil, err
+	}
+	if !opts.Symmetry.none() {
+		if name != "" && name != Asymmetrical && slices.Contains(Names, name) {
+			return nil, fmt.Errorf("%w: the %s generator cannot follow a symmetry, use %s", ErrInvalidSymmetry, name, Asymmetrical)
+		}
+		if err := opts.Symmetry.checkBoard(b); err != nil {
+			return nil, err
+		}
+	}
+	if len(opts.Pinned) > 0 && name != "" && name != Asymmetrical && slices.Contains(Names, name) {
+		return nil, fmt.Errorf("%w: the %s generator cannot place pinned words, use %s", ErrInvalidPin, name, Asymmetrical)
+	}
+	switch name {
+	case "", Asymmetrical:
+		g := NewAsymmetricalGenerator(b, pool, opts.Rand)
+		g.Budget = opts.Budget
+		g.Symmetry = opts.Symmetry
+		g.Pinned = opts.Pinned
+		if opts.Objective != (Objective{}) {
+			g.Objective = opts.Objective
+		}
+		return g, nil
+	case Checkpoint:
+		g := NewCheckpointGenerator(b, pool, opts.Rand)
+		g.Budget = opts.Budget
+		if opts.Objective != (Objective{}) {
+			g.Objective = opts.Objective
+		}
+		return g, nil
+	case CSP:
+		g := NewCSPGenerator(b, pool, opts.Rand)
+		g.Budget = opts.Budget
+		if opts.Objective != (Objective{}) {
+			g.Objective = opts.Objective
+		}
+		return g, nil
+	case Dense:
+		g := NewDenseGenerator(b, pool, opts.Rand)
+		g.Budget = opts.Budget
+		g.Dictionary = opts.Dictionary
+		if opts.Objective != (Objective{}) {
+			g.Objective = opts.Objective
+		}
+		return g, nil
+	case American:
+		g := NewAmericanGenerator(b, pool, opts.Rand)
+		g.Budget = opts.Budget
+		g.Dictionary = opts.Dictionary
+		if opts.Objective != (Objective{}) {
+			g.Objective = opts.Objective
+		}
+		return g, nil
+	case Annealing:
+		g := NewAnnealingGenerator(b, pool, opts.Rand)
+		g.Budget = opts.Budget
+		if opts.Objective != (Objective{}) {
+			g.Objective = opts.Objective
+		}
+		return g, nil
+	case Genetic:
+		g := NewGeneticGenerator(b, pool, opts.Rand)
+		g.Budget = opts.Budget
+		g.Workers = opts.Workers
+		if opts.Objective != (Objective{}) {
+			g.Objective = opts.Objective
+		}
+		return g, nil
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownGenerator, name)
+}
+
+// Generator defines the interface for generating crossword puzzles. A
+// generator keeps the state of its runs to itself, so generators on
+// different boards can run at the same time; a single generator must not
+// run Generate concurrently.
 type Generator interface {
-	Generate() error // Generate the crossword puzzle, returning an error if unsuccessful.
+	// Generate the crossword puzzle until all words are placed, the search
+	// is exhausted, ctx is done or a budget runs out. The result tells which
+	// one happened; an error is returned only if the generator cannot run.
+	//
+	// A backtracking search that places all words goes on for a layout with
+	// a higher score while the budget lasts, unless the Objective weighs
+	// only the words or the budget has no limit.
+	Generate(ctx context.Context) (Result, error)
 }
 
 // BaseGenerator provides a basic structure and common functionality for
 // crossword generators.
 type BaseGenerator struct {
-	Board *models.Board // A reference to the board where the crossword will be generated.
+	Board *board.Board // A reference to the board where the crossword will be generated.
 }
 
 // NewBaseGenerator creates a new instance of BaseGenerator with
 // specified board boundaries.
-func NewBaseGenerator(b *models.Board) *BaseGenerator {
+func NewBaseGenerator(b *board.Board) *BaseGenerator {
 	return &BaseGenerator{
 		Board: b,
 	}
@@ -28,6 +162,6 @@ func NewBaseGenerator(b *models.Board) *BaseGenerator {
 // Generate is a placeholder to satisfy the Generator interface.
 // Specific generator implementations should override this method with
 // actual logic.
-func (bg *BaseGenerator) Generate() error {
-	return errors.New("Generate method not implemented")
+func (bg *BaseGenerator) Generate(ctx context.Context) (Result, error) {
+	return Result{}, errors.New("Generate method not implemented")
 }
@@ -0,0 +1,224 @@
+package generators_test
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/generators"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+)
+
+func newGenerator(t *testing.T, name string, size int, list []string, budget generators.Budget) (*board.Board, generators.Generator) {
+	t.Helper()
+	return newGeneratorWith(t, name, size, list, generators.Options{Budget: budget})
+}
+
+func newGeneratorWith(t *testing.T, name string, size int, list []string, opts generators.Options) (*board.Board, generators.Generator) {
+	t.Helper()
+	bounds, err := board.NewBoundsRectangle(size, size)
+	if err != nil {
+		t.Fatalf("Failed to create bounds: %s", err)
+	}
+	b := board.NewBoard(bounds, len(list), &board.OSFileWriter{})
+	pool := words.NewPool()
+	pool.LoadWords(list)
+	g, err := generators.New(name, b, pool, opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	return b, g
+}
+
+// outcome is how a generation run ends.
+type outcome struct {
+	reason generators.Reason
+	placed int
+}
+
+// TestGenerators_Generate runs every generator until it places all words,
+// runs out of placements, out of budget or out of time, and checks the best
+// layout it found. The free-form
+// generators place a few words on a 7x7 board. The dense generators fill a
+// 4x4 grid from the rows and columns of a letter grid, or fail to fill it
+// from words that do not cross.
+func TestGenerators_Generate(t *testing.T) {
+	grid := letterGrid(rand.New(rand.NewSource(1)), 4)
+	var dictionary []words.Entry
+	for _, w := range grid {
+		dictionary = append(dictionary, words.Entry{Word: w})
+	}
+	dense := map[string]bool{generators.Dense: true, generators.American: true}
+
+	tests := []struct {
+		name     string
+		fits     bool // Whether all words fit on the board.
+		budget   generators.Budget
+		canceled bool
+		want     outcome
+		// others holds the outcomes of the generators that end differently.
+		others map[string]outcome
+	}{
+		{
+			name: "complete",
+			fits: true,
+			want: outcome{generators.Complete, 3},
+			others: map[string]outcome{
+				generators.Dense:    {generators.Complete, 4}, // Black squares leave four words.
+				generators.American: {generators.Complete, 8},
+			},
+		},
+		{
+			name: "exhausted",
+			want: outcome{generators.Exhausted, 2},
+			others: map[string]outcome{
+				generators.Dense:    {generators.Exhausted, 0},
+				generators.American: {generators.Exhausted, 0},
+			},
+		},
+		{
+			name:   "node budget",
+			fits:   true,
+			budget: generators.Budget{MaxNodes: 2},
+			want:   outcome{generators.BudgetExceeded, 2},
+			others: map[string]outcome{
+				// Both build a whole first layout before checking the budget.
+				generators.Annealing: {generators.Complete, 3},
+				generators.Genetic:   {generators.Complete, 3},
+				// A grid with two words across is no crossword.
+				generators.Dense:    {generators.BudgetExceeded, 0},
+				generators.American: {generators.BudgetExceeded, 0},
+			},
+		},
+		{
+			name:   "backtrack budget",
+			budget: generators.Budget{MaxBacktracks: 1},
+			want:   outcome{generators.BudgetExceeded, 2},
+			others: map[string]outcome{
+				generators.Dense:    {generators.Exhausted, 0},
+				generators.American: {generators.Exhausted, 0},
+				generators.Genetic:  {generators.Exhausted, 2}, // Evolution takes nothing back.
+			},
+		},
+		{
+			name:     "canceled",
+			canceled: true,
+			want:     outcome{generators.Canceled, 1},
+			others: map[string]outcome{
+				generators.Dense:     {generators.Canceled, 0},
+				generators.American:  {generators.Canceled, 0},
+				generators.Annealing: {generators.Canceled, 2}, // The first layout is greedy.
+				generators.Genetic:   {generators.Canceled, 0}, // No layout is decoded.
+			},
+		},
+	}
+	for _, name := range generators.Names {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				want, ok := tt.others[name]
+				if !ok {
+					want = tt.want
+				}
+
+				size, list, opts := 7, []string{"haus", "see", "xyz"}, generators.Options{Budget: tt.budget}
+				if tt.fits {
+					list = []string{"haus", "see", "eis"}
+				}
+				if dense[name] {
+					size, list = 4, []string{"haus", "baum", "maus", "laus"}
+					if tt.fits {
+						list, opts.Dictionary = grid[:1], dictionary
+						opts.Rand = rand.New(rand.NewSource(1))
+					}
+				}
+				b, g := newGeneratorWith(t, name, size, list, opts)
+				ctx := context.Background()
+				if tt.canceled {
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithCancel(ctx)
+					cancel()
+				}
+
+				res, err := g.Generate(ctx)
+				if err != nil {
+					t.Fatalf("Unexpected error: %s", err)
+				}
+				if res.Reason != want.reason {
+					t.Errorf("Incorrect reason, got: %s, want: %s", res.Reason, want.reason)
+				}
+				if res.Placed != want.placed || b.BestWordCount != want.placed {
+					t.Errorf("Incorrect best solution, got: %d (board: %d) words, want: %d", res.Placed, b.BestWordCount, want.placed)
+				}
+				if want.reason == generators.Complete && b.WordCount != want.placed {
+					t.Errorf("Incorrect board after a complete run, got: %d words, want: %d", b.WordCount, want.placed)
+				}
+				if want.reason != generators.Complete && b.WordCount != 0 {
+					t.Errorf("Expected an empty board after an incomplete run, got: %d words", b.WordCount)
+				}
+				checkLayout(t, b)
+			})
+		}
+	}
+}
+
+// TestGenerators_BestCompleteLayout checks that the backtracking generators
+// go on after a layout with all words while the budget lasts, and keep the
+// one with the highest score rather than the first one found.
+func TestGenerators_BestCompleteLayout(t *testing.T) {
+	list := []string{"haus", "hase", "see", "eis", "ast", "tee"}
+	objective := generators.Objective{Words: 1, Compactness: 1}
+	for _, name := range []string{generators.Asymmetrical, generators.Checkpoint, generators.CSP} {
+		t.Run(name, func(t *testing.T) {
+			generate := func(o generators.Objective, budget generators.Budget) (generators.Result, float64) {
+				b, g := newGeneratorWith(t, name, 8, list, generators.Options{Rand: rand.New(rand.NewSource(2)), Objective: o, Budget: budget})
+				res, err := g.Generate(context.Background())
+				if err != nil {
+					t.Fatalf("Unexpected error: %s", err)
+				}
+				if res.Reason != generators.Complete || b.WordCount != len(list) {
+					t.Fatalf("Incorrect result, got: %d words %s, want: %d words %s", b.WordCount, res.Reason, len(list), generators.Complete)
+				}
+				if b.BestWordCount != len(list) {
+					t.Fatalf("Expected the best solution on the board, got: %d words", b.BestWordCount)
+				}
+				return res, objective.Score(b.Cells)
+			}
+
+			firstRes, first := generate(generators.Objective{Words: 1}, generators.Budget{MaxBacktracks: 2000})
+			bestRes, best := generate(objective, generators.Budget{MaxBacktracks: 2000})
+			if best <= first {
+				t.Errorf("Expected a higher score than the first complete layout, got: %v, first: %v", best, first)
+			}
+			if bestRes.Nodes <= firstRes.Nodes {
+				t.Errorf("Expected the search to go on, got: %d nodes, first: %d", bestRes.Nodes, firstRes.Nodes)
+			}
+			if _, unbounded := generate(objective, generators.Budget{}); unbounded != first {
+				t.Errorf("Expected the first complete layout without a budget, got: %v, want: %v", unbounded, first)
+			}
+		})
+	}
+}
+
+// school holds words that cross each other in many ways, so generators
+// try many placements over letters already on the board.
+var school = []string{"schulbank", "tischdecke", "apfelbaum", "katzenklo", "schule", "katze", "birne", "baum", "haus", "apfel", "tisch", "stuhl", "blume", "ente"}
+
+// checkSeeds runs the named generator on the school words with many seeds and
+// checks every layout it finds.
+func checkSeeds(t *testing.T, name string) {
+	t.Helper()
+	for seed := int64(1); seed <= 10; seed++ {
+		b, g := newGeneratorWith(t, name, 12, school, generators.Options{
+			Rand:   rand.New(rand.NewSource(seed)),
+			Budget: generators.Budget{MaxBacktracks: 2000},
+		})
+		if _, err := g.Generate(context.Background()); err != nil {
+			t.Fatalf("Unexpected error with seed %d: %s", seed, err)
+		}
+		if b.BestWordCount == 0 {
+			t.Errorf("Expected words on the board with seed %d", seed)
+		}
+		checkLayout(t, b)
+	}
+}
@@ -0,0 +1,323 @@
+package generators
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+)
+
+// The defaults of the GeneticGenerator settings.
+const (
+	DefaultPopulation   = 40  // Layouts per generation.
+	DefaultGenerations  = 40  // Generations per call to Generate.
+	DefaultMutationRate = 0.1 // Chance of each gene to mutate.
+	DefaultElite        = 2   // Best layouts kept unchanged.
+	tournamentSize      = 3   // Layouts competing to be a parent.
+	geneticPasses       = 2   // Passes over the words left off the board.
+)
+
+// noFitness is the fitness of a genome not evaluated yet.
+var noFitness = math.Inf(-1)
+
+// GeneticGenerator evolves layouts with a genetic algorithm. A layout is
+// encoded as a genome: the order the words are placed in and, for every
+// word, a choice among the placements it finds when its turn comes. The
+// first word goes to the middle of the board; a word without placements is
+// tried again after the others. The fitness of a genome is the Objective
+// score of its layout.
+//
+// Each generation keeps the Elite best genomes and breeds the rest from
+// parents picked by tournament, with order crossover of the word orders,
+// uniform crossover of the choices, and mutations that swap two words or
+// redraw a choice. The genomes of a generation are evaluated in parallel by
+// Workers goroutines, each on a board of its own. All random choices are
+// made between the evaluations, so a seed gives the same board whatever the
+// number of workers, unless ctx or the budget stops a generation halfway.
+type GeneticGenerator struct {
+	*BaseGenerator
+	WordPool *words.Pool
+	// Rand draws the genomes, the parents, the crossovers and mutations.
+	// Without it, they are drawn from a fixed seed.
+	Rand      *rand.Rand
+	Budget    Budget    // Limits the work of each call to Generate.
+	Objective Objective // What makes a layout fit.
+
+	Population   int     // The number of genomes per generation.
+	Generations  int     // The max number of generations per call to Generate.
+	MutationRate float64 // The chance of each gene to mutate, between 0 and 1.
+	Elite        int     // The number of best genomes passed on unchanged.
+	Workers      int     // The number of genomes evaluated at a time; the number of CPUs if zero.
+
+	run // The state of the current call to Generate.
+}
+
+// NewGeneticGenerator returns a genetic algorithm generator with the
+// default settings.
+func NewGeneticGenerator(board *board.Board, pool *words.Pool, rng *rand.Rand) *GeneticGenerator {
+	return &GeneticGenerator{
+		BaseGenerator: NewBaseGenerator(board),
+		WordPool:      pool,
+		Rand:          rng,
+		Objective:     DefaultObjective,
+		Population:    DefaultPopulation,
+		Generations:   DefaultGenerations,
+		MutationRate:  DefaultMutationRate,
+		Elite:         DefaultElite,
+	}
+}
+
+// genome encodes a layout: the order of the words, by index in the pool,
+// and the choice of placement of each word, between 0 and 1.
+type genome struct {
+	order   []int
+	choices []float64
+	fitness float64
+	placed  int // The number of words of the layout.
+}
+
+// Generate evolves layouts of the words of the pool. It stops early when
+// ctx is done or the budget runs out. Words already on the board are
+// removed. The best layout found is saved as the best solution of the board
+// if it beats the one saved, and the board is left empty unless all words
+// were placed, so Generate can be called again for another attempt.
+func (gg *GeneticGenerator) Generate(ctx context.Context) (Result, error) {
+	if gg.Board == nil || len(gg.WordPool.Entries) == 0 {
+		return Result{}, fmt.Errorf("uninitialized board or pool, or empty words list")
+	}
+	gg.start(ctx, gg.Budget)
+	rng := gg.Rand
+	if rng == nil {
+		rng = rand.New(rand.NewSource(1))
+	}
+	entries := gg.WordPool.Entries
+	size := max(gg.Population, 2)
+	elite := min(gg.Elite, size)
+
+	population := make([]*genome, size)
+	for i := range population {
+		population[i] = randomGenome(rng, len(entries))
+	}
+	// The first genome places the words from the longest to the shortest,
+	// like the backtracking generators.
+	for i := range population[0].order {
+		population[0].order[i] = i
+	}
+
+	var best *genome
+	reason := Exhausted
+	for generation := 0; generation < gg.Generations; generation++ {
+		if gg.shouldStop() {
+			reason = gg.stop
+			break
+		}
+		evaluated := gg.evaluate(population, entries)
+		sortGenomes(population)
+		if population[0].fitness != noFitness && (best == nil || population[0].fitness > best.fitness) {
+			best = population[0]
+		}
+		if !evaluated {
+			gg.shouldStop()
+			reason = gg.stop
+			break
+		}
+		if best.placed == len(entries) && gg.Objective.wordsOnly() {
+			break
+		}
+
+		next := make([]*genome, 0, size)
+		next = append(next, population[:elite]...)
+		for len(next) < size {
+			child := crossover(rng, tournament(rng, population), tournament(rng, population))
+			child.mutate(rng, gg.MutationRate)
+			next = append(next, child)
+		}
+		population = next
+	}
+
+	if best == nil {
+		return gg.result(gg.Board, reason), nil
+	}
+	clearBoard(gg.Board)
+	decode(gg.Board, entries, best)
+	saveIfBetter(gg.Board, gg.Objective)
+	if best.placed == len(entries) {
+		return gg.result(gg.Board, Complete), nil
+	}
+	clearBoard(gg.Board)
+	return gg.result(gg.Board, reason), nil
+}
+
+// evaluate decodes the genomes not evaluated yet, in parallel, and sets
+// their fitness. It reports false if ctx was done or the node budget ran
+// out before all genomes were evaluated; the budget is checked before each
+// genome, so it is exceeded by one decode per worker at most.
+func (gg *GeneticGenerator) evaluate(population []*genome, entries []words.Entry) bool {
+	workers := gg.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	base := gg.Board.Clone()
+	clearBoard(base)
+
+	jobs := make(chan *genome)
+	var nodes atomic.Int64
+	nodes.Store(int64(gg.nodes))
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b := base.Clone()
+			for g := range jobs {
+				if gg.ctx.Err() != nil || (gg.budget.MaxNodes > 0 && nodes.Load() >= int64(gg.budget.MaxNodes)) {
+					continue
+				}
+				nodes.Add(int64(decode(b, entries, g)))
+				g.fitness = gg.Objective.Score(b.Cells)
+				g.placed = b.WordCount
+				clearBoard(b)
+			}
+		}()
+	}
+	for _, g := range population {
+		if g.fitness == noFitness {
+			jobs <- g
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	gg.nodes = int(nodes.Load())
+	for _, g := range population {
+		if g.fitness == noFitness {
+			return false
+		}
+	}
+	return true
+}
+
+// decode places the words on the empty board b as the genome says, and
+// returns the number of words placed. The first word that fits across the
+// middle of the board starts the layout; every other word takes the
+// placement its choice points to among those found, and words without one
+// are tried again after the others.
+func decode(b *board.Board, entries []words.Entry, g *genome) int {
+	var left []int
+	for _, i := range g.order {
+		if b.WordCount == 0 {
+			if placeFirstWord(b, entries[i]) != nil {
+				left = append(left, i)
+			}
+			continue
+		}
+		if !placeChoice(b, entries[i], g.choices[i]) {
+			left = append(left, i)
+		}
+	}
+	for pass := 0; pass < geneticPasses && len(left) > 0 && b.WordCount > 0; pass++ {
+		var still []int
+		for _, i := range left {
+			if !placeChoice(b, entries[i], g.choices[i]) {
+				still = append(still, i)
+			}
+		}
+		left = still
+	}
+	return b.WordCount
+}
+
+// placeChoice places the entry at the placement the choice points to, and
+// reports false if the entry has no placement.
+func placeChoice(b *board.Board, entry words.Entry, choice float64) bool {
+	placements := crossingPlacements(b, entry.Word)
+	if len(placements) == 0 {
+		return false
+	}
+	p := placements[min(int(choice*float64(len(placements))), len(placements)-1)]
+	b.PlaceEntryAt(p.Start, entry, p.Direction)
+	return true
+}
+
+// randomGenome returns a genome with a random order of n words and random
+// choices.
+func randomGenome(rng *rand.Rand, n int) *genome {
+	g := &genome{order: rng.Perm(n), choices: make([]float64, n), fitness: noFitness}
+	for i := range g.choices {
+		g.choices[i] = rng.Float64()
+	}
+	return g
+}
+
+// tournament returns the fittest of a few random genomes.
+func tournament(rng *rand.Rand, population []*genome) *genome {
+	best := population[rng.Intn(len(population))]
+	for i := 1; i < tournamentSize; i++ {
+		if g := population[rng.Intn(len(population))]; g.fitness > best.fitness {
+			best = g
+		}
+	}
+	return best
+}
+
+// crossover returns a child of the genomes a and b. Its order keeps a random
+// slice of the order of a and fills the rest in the order of b; each choice
+// comes from a or b at random.
+func crossover(rng *rand.Rand, a, b *genome) *genome {
+	n := len(a.order)
+	child := &genome{order: make([]int, 0, n), choices: make([]float64, n), fitness: noFitness}
+	lo := rng.Intn(n)
+	hi := lo + rng.Intn(n-lo) + 1
+	kept := make(map[int]bool, hi-lo)
+	for _, w := range a.order[lo:hi] {
+		kept[w] = true
+	}
+	rest := make([]int, 0, n-(hi-lo))
+	for _, w := range b.order {
+		if !kept[w] {
+			rest = append(rest, w)
+		}
+	}
+	child.order = append(child.order, rest[:lo]...)
+	child.order = append(child.order, a.order[lo:hi]...)
+	child.order = append(child.order, rest[lo:]...)
+
+	for i := range child.choices {
+		child.choices[i] = a.choices[i]
+		if rng.Intn(2) == 0 {
+			child.choices[i] = b.choices[i]
+		}
+	}
+	return child
+}
+
+// mutate swaps each word of the order with a random one and redraws each
+// choice with the chance rate.
+func (g *genome) mutate(rng *rand.Rand, rate float64) {
+	for i := range g.order {
+		if rng.Float64() < rate {
+			j := rng.Intn(len(g.order))
+			g.order[i], g.order[j] = g.order[j], g.order[i]
+		}
+	}
+	for i := range g.choices {
+		if rng.Float64() < rate {
+			g.choices[i] = rng.Float64()
+		}
+	}
+}
+
+// sortGenomes sorts the genomes from the fittest to the least fit, keeping
+// the order of equally fit ones.
+func sortGenomes(population []*genome) {
+	sort.SliceStable(population, func(i, j int) bool {
+		return population[i].fitness > population[j].fitness
+	})
+}
@@ -0,0 +1,111 @@
+package generators_test
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/generators"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+)
+
+// TestGeneticGenerator_Workers checks that the number of workers does not
+// change the board a seed gives, and that evolution places more words than
+// backtracking on a crowded board. Run it with -race to check the workers
+// share no state.
+func TestGeneticGenerator_Workers(t *testing.T) {
+	generate := func(workers int) (*board.Board, generators.Result) {
+		bounds, err := board.NewBoundsRectangle(11, 11)
+		if err != nil {
+			t.Fatalf("Failed to create bounds: %s", err)
+		}
+		b := board.NewBoard(bounds, len(crowded), &board.OSFileWriter{})
+		pool := words.NewPool()
+		pool.LoadWords(crowded)
+		g := generators.NewGeneticGenerator(b, pool, rand.New(rand.NewSource(1)))
+		g.Population, g.Generations, g.Workers = 20, 15, workers
+		res, err := g.Generate(context.Background())
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		return b, res
+	}
+
+	b, res := generate(1)
+	parallel, parallelRes := generate(4)
+	if res != parallelRes || !reflect.DeepEqual(b.BestPlacedWords, parallel.BestPlacedWords) {
+		t.Errorf("Incorrect board with 4 workers, got: %+v, want: %+v", parallel.BestPlacedWords, b.BestPlacedWords)
+	}
+	checkLayout(t, b)
+
+	_, backtracking := generateCrowded(t, generators.Asymmetrical)
+	if res.Placed <= backtracking.Placed {
+		t.Errorf("Expected more words than backtracking, got: %d, backtracking: %d", res.Placed, backtracking.Placed)
+	}
+}
+
+// TestGeneticGenerator_NodeBudget checks that the node budget stops the
+// evaluation of a generation, not only the next one.
+func TestGeneticGenerator_NodeBudget(t *testing.T) {
+	const maxNodes, workers = 30, 4
+	bounds, err := board.NewBoundsRectangle(11, 11)
+	if err != nil {
+		t.Fatalf("Failed to create bounds: %s", err)
+	}
+	b := board.NewBoard(bounds, len(crowded), &board.OSFileWriter{})
+	pool := words.NewPool()
+	pool.LoadWords(crowded)
+	g, err := generators.New(generators.Genetic, b, pool, generators.Options{
+		Rand:    rand.New(rand.NewSource(1)),
+		Budget:  generators.Budget{MaxNodes: maxNodes},
+		Workers: workers,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if got := g.(*generators.GeneticGenerator).Workers; got != workers {
+		t.Errorf("Incorrect number of workers, got: %d, want: %d", got, workers)
+	}
+
+	res, err := g.Generate(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if res.Reason != generators.BudgetExceeded {
+		t.Errorf("Incorrect reason, got: %s, want: %s", res.Reason, generators.BudgetExceeded)
+	}
+	// Each worker may start one more decode before it sees the budget.
+	if limit := maxNodes + workers*len(crowded); res.Nodes > limit {
+		t.Errorf("Too many nodes, got: %d, want at most: %d", res.Nodes, limit)
+	}
+	if res.Placed == 0 {
+		t.Errorf("Expected the best of the evaluated layouts to be kept")
+	}
+}
+
+// TestGeneticGenerator_Seeds checks that the choices of placements never
+// put a word over different letters.
+func TestGeneticGenerator_Seeds(t *testing.T) {
+	checkSeeds(t, generators.Genetic)
+}
+
+// TestGeneticGenerator_WordsOnBoard checks that the best layout is decoded
+// on a cleared board, not over the words left on it.
+func TestGeneticGenerator_WordsOnBoard(t *testing.T) {
+	list := []string{"haus", "see", "eis", "tee"}
+	b, g := newGenerator(t, generators.Genetic, 9, list, generators.Budget{})
+	if err := b.PlaceEntryAt(board.Location{X: 0, Y: 8}, words.Entry{Word: "haus"}, board.Across); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	res, err := g.Generate(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if res.Reason != generators.Complete || b.BestWordCount != len(list) {
+		t.Fatalf("Incorrect result, got: %d words %s, want: %d words %s", b.BestWordCount, res.Reason, len(list), generators.Complete)
+	}
+	checkLayout(t, b)
+}
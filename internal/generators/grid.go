@@ -0,0 +1,214 @@
+package generators
+
+import (
+	"math/rand"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+)
+
+// grid is a pattern of black squares for a dense board. Cells are indexed
+// row by row, y*w + x.
+type grid struct {
+	w, h  int
+	black []bool
+}
+
+// slot is a run of white cells long enough to hold a word.
+type slot struct {
+	Placement
+	cells []int
+}
+
+func newGrid(w, h int) *grid {
+	return &grid{w: w, h: h, black: make([]bool, w*h)}
+}
+
+// maskGrid returns a grid of the size of the board with black squares on
+// the cells the mask of the board blocks. If symmetric, the mirrors of the
+// blocked cells are black too.
+func maskGrid(b *board.Board, symmetric bool) *grid {
+	g := newGrid(b.Bounds.Width(), b.Bounds.Height())
+	for c := range g.black {
+		if !b.Allowed(c%g.w, c/g.w) {
+			g.setBlack(c, symmetric)
+		}
+	}
+	return g
+}
+
+// blackCount returns the number of black squares.
+func (g *grid) blackCount() int {
+	n := 0
+	for _, black := range g.black {
+		if black {
+			n++
+		}
+	}
+	return n
+}
+
+// runs returns the maximal runs of white cells in both directions, across
+// first, including runs of a single cell.
+func (g *grid) runs() []slot {
+	var runs []slot
+	for _, dir := range []board.Direction{board.Across, board.Down} {
+		dx, dy := deltas(dir)
+		lines, length := g.h, g.w
+		if dir == board.Down {
+			lines, length = g.w, g.h
+		}
+		for line := 0; line < lines; line++ {
+			var run *slot
+			for i := 0; i <= length; i++ {
+				x, y := i*dx+line*dy, i*dy+line*dx
+				if i == length || g.black[y*g.w+x] {
+					if run != nil {
+						runs = append(runs, *run)
+						run = nil
+					}
+					continue
+				}
+				if run == nil {
+					run = &slot{Placement: Placement{Start: board.Location{X: x, Y: y}, Direction: dir}}
+				}
+				run.cells = append(run.cells, y*g.w+x)
+			}
+		}
+	}
+	return runs
+}
+
+// slots returns the runs of white cells that hold a word, those of at least
+// two cells.
+func (g *grid) slots() []slot {
+	var slots []slot
+	for _, run := range g.runs() {
+		if len(run.cells) >= 2 {
+			slots = append(slots, run)
+		}
+	}
+	return slots
+}
+
+// gridRules are the rules a pattern of black squares has to follow.
+type gridRules struct {
+	maxBlack  int          // The max number of black squares.
+	minLength int          // The min number of letters of a word.
+	lengths   map[int]bool // The lengths there are words of.
+	checked   bool         // Every white cell is part of an across and a down word.
+	symmetric bool         // The pattern looks the same turned by 180 degrees.
+}
+
+// badCells returns the cells of the runs that cannot hold a word: runs
+// shorter than minLength or without words of their length, and white cells
+// that are in no word at all. If every cell has to be checked, single white
+// cells are bad runs too.
+func (g *grid) badCells(rules gridRules) [][]int {
+	var bad [][]int
+	inWord := make([]bool, len(g.black))
+	for _, run := range g.runs() {
+		n := len(run.cells)
+		if n == 1 && !rules.checked {
+			continue
+		}
+		if n < rules.minLength || !rules.lengths[n] {
+			bad = append(bad, run.cells)
+			continue
+		}
+		for _, c := range run.cells {
+			inWord[c] = true
+		}
+	}
+	for c, black := range g.black {
+		if !black && !inWord[c] {
+			bad = append(bad, []int{c})
+		}
+	}
+	return bad
+}
+
+// mirror returns the cell the cell c turns into when the grid is turned by
+// 180 degrees.
+func (g *grid) mirror(c int) int {
+	return len(g.black) - 1 - c
+}
+
+// setBlack turns the cell c black, and its mirror too if the pattern is
+// symmetric.
+func (g *grid) setBlack(c int, symmetric bool) {
+	g.black[c] = true
+	if symmetric {
+		g.black[g.mirror(c)] = true
+	}
+}
+
+// connected reports whether the white cells form a single area.
+func (g *grid) connected() bool {
+	start := -1
+	white := 0
+	for c, black := range g.black {
+		if !black {
+			white++
+			start = c
+		}
+	}
+	if start < 0 {
+		return false
+	}
+
+	seen := make([]bool, len(g.black))
+	seen[start] = true
+	queue := []int{start}
+	reached := 0
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		reached++
+		x, y := c%g.w, c/g.w
+		for _, n := range [][2]int{{x - 1, y}, {x + 1, y}, {x, y - 1}, {x, y + 1}} {
+			if n[0] < 0 || n[1] < 0 || n[0] >= g.w || n[1] >= g.h {
+				continue
+			}
+			next := n[1]*g.w + n[0]
+			if !g.black[next] && !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return reached == white
+}
+
+// randomGrid draws a pattern following the rules, starting from the black
+// squares of base. The cells marked in white stay white. It scatters a few
+// black squares first, so the patterns differ, then turns cells of runs
+// that cannot hold a word black until all can. It reports false if the
+// pattern needs too many black squares or falls apart.
+func randomGrid(rng *rand.Rand, base *grid, white []bool, rules gridRules) (*grid, bool) {
+	g := &grid{w: base.w, h: base.h, black: append([]bool(nil), base.black...)}
+	canBlacken := func(c int) bool {
+		return white == nil || (!white[c] && !(rules.symmetric && white[g.mirror(c)]))
+	}
+	for n := rng.Intn(rules.maxBlack/2 + 1); n > 0; n-- {
+		if c := rng.Intn(len(g.black)); canBlacken(c) {
+			g.setBlack(c, rules.symmetric)
+		}
+	}
+	for g.blackCount() <= rules.maxBlack {
+		bad := g.badCells(rules)
+		if len(bad) == 0 {
+			return g, g.connected()
+		}
+		var cells []int
+		for _, c := range bad[rng.Intn(len(bad))] {
+			if canBlacken(c) {
+				cells = append(cells, c)
+			}
+		}
+		if len(cells) == 0 {
+			return nil, false
+		}
+		g.setBlack(cells[rng.Intn(len(cells))], rules.symmetric)
+	}
+	return nil, false
+}
@@ -0,0 +1,60 @@
+package generators
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+)
+
+func TestRandomGrid_Symmetric(t *testing.T) {
+	rules := gridRules{
+		maxBlack:  30,
+		minLength: 3,
+		lengths:   map[int]bool{3: true, 4: true, 5: true, 6: true, 7: true, 8: true, 9: true},
+		checked:   true,
+		symmetric: true,
+	}
+	rng := rand.New(rand.NewSource(1))
+	drawn := 0
+	for i := 0; i < 50; i++ {
+		g, ok := randomGrid(rng, newGrid(9, 9), nil, rules)
+		if !ok {
+			continue
+		}
+		drawn++
+
+		if n := g.blackCount(); n > rules.maxBlack {
+			t.Errorf("Too many black squares, got: %d, want at most: %d", n, rules.maxBlack)
+		}
+		for c := range g.black {
+			if g.black[c] != g.black[g.mirror(c)] {
+				t.Fatalf("Expected a symmetric pattern, cell %d differs from cell %d", c, g.mirror(c))
+			}
+		}
+		across, down := make([]bool, len(g.black)), make([]bool, len(g.black))
+		for _, run := range g.runs() {
+			if len(run.cells) < rules.minLength {
+				t.Fatalf("Incorrect run at %v, got: %d cells, want at least: %d", run.Start, len(run.cells), rules.minLength)
+			}
+			for _, c := range run.cells {
+				if run.Direction == board.Across {
+					across[c] = true
+				} else {
+					down[c] = true
+				}
+			}
+		}
+		for c, black := range g.black {
+			if !black && (!across[c] || !down[c]) {
+				t.Fatalf("Expected cell %d to be checked", c)
+			}
+		}
+		if !g.connected() {
+			t.Fatalf("Expected connected white cells")
+		}
+	}
+	if drawn == 0 {
+		t.Fatalf("Expected at least one pattern")
+	}
+}
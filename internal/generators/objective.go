@@ -0,0 +1,254 @@
+package generators
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+)
+
+// Objective weighs what makes a layout good. The score of a layout is the
+// sum of its measures, each times its weight; a negative weight makes a
+// layout better the less it has of a measure. The AnnealingGenerator and
+// the GeneticGenerator optimize the score, the other generators keep the
+// layout of the highest score among those with the most words, and Build
+// keeps the board of the highest score of all attempts.
+type Objective struct {
+	Words         float64 // The weight of each word placed.
+	Intersections float64 // The weight of each cell shared by two words.
+	// Compactness is the weight of the share of the board outside the
+	// smallest rectangle around the letters, between 0 and 1.
+	Compactness float64
+	Interlock   float64 // The weight of the number of intersections per word.
+	// Checked is the weight of the share of letters that belong to two
+	// words, between 0 and 1.
+	Checked float64
+	// Density is the weight of the share of the cells of the smallest
+	// rectangle around the letters that hold a letter, between 0 and 1.
+	Density float64
+	// Aspect is the weight of how square the smallest rectangle around the
+	// letters is: its shorter side divided by its longer one.
+	Aspect float64
+	// Spread is the weight of how evenly the letters are spread over the
+	// nine parts of the smallest rectangle around them, between 0 for
+	// letters in one part and 1 for as many letters in every part.
+	Spread float64
+}
+
+// DefaultObjective places as many words as possible. Intersections and
+// compactness only decide between layouts with the same number of words.
+var DefaultObjective = Objective{Words: 1, Intersections: 0.01, Compactness: 0.1}
+
+// Objectives holds the objectives that can be chosen by name. Their weights
+// besides Words add up to less than one word.
+var Objectives = map[string]Objective{
+	"default": DefaultObjective,
+	// Compact prefers small, heavily interlocked layouts.
+	"compact": {Words: 1, Interlock: 0.2, Checked: 0.2, Density: 0.2},
+	// Sparse prefers open layouts with few crossings, which are easier to
+	// solve.
+	"sparse": {Words: 1, Checked: -0.3, Density: -0.3, Spread: 0.3},
+}
+
+// ObjectiveNames lists the names of the Objectives.
+var ObjectiveNames = []string{"default", "compact", "sparse"}
+
+// measures lists the measures of a layout, each with its weight in an
+// Objective. A new measure takes a weight and an entry here.
+var measures = []struct {
+	name   string
+	weight func(o *Objective) *float64
+	value  func(l layoutStats) float64
+}{
+	{"words", func(o *Objective) *float64 { return &o.Words }, func(l layoutStats) float64 { return float64(l.words) }},
+	{"intersections", func(o *Objective) *float64 { return &o.Intersections }, func(l layoutStats) float64 { return float64(l.intersections) }},
+	{"compactness", func(o *Objective) *float64 { return &o.Compactness }, func(l layoutStats) float64 {
+		return 1 - float64(l.boxWidth*l.boxHeight)/float64(l.cells)
+	}},
+	{"interlock", func(o *Objective) *float64 { return &o.Interlock }, func(l layoutStats) float64 {
+		return float64(l.intersections) / float64(max(l.words, 1))
+	}},
+	{"checked", func(o *Objective) *float64 { return &o.Checked }, func(l layoutStats) float64 {
+		return float64(l.intersections) / float64(l.letters)
+	}},
+	{"density", func(o *Objective) *float64 { return &o.Density }, func(l layoutStats) float64 {
+		return float64(l.letters) / float64(l.boxWidth*l.boxHeight)
+	}},
+	{"aspect", func(o *Objective) *float64 { return &o.Aspect }, func(l layoutStats) float64 {
+		return float64(min(l.boxWidth, l.boxHeight)) / float64(max(l.boxWidth, l.boxHeight))
+	}},
+	{"spread", func(o *Objective) *float64 { return &o.Spread }, func(l layoutStats) float64 { return l.spread }},
+}
+
+// ParseObjective parses weights like "words=1,intersections=0.5". Weights
+// not given are zero. A name of the Objectives instead of a weight starts
+// from its weights, so "compact,spread=0.1" adds a weight to it.
+func ParseObjective(s string) (Objective, error) {
+	var o Objective
+	for _, field := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok {
+			preset, ok := Objectives[name]
+			if !ok {
+				return Objective{}, fmt.Errorf("invalid objective weight %q, want name=weight or one of %s", field, strings.Join(ObjectiveNames, ", "))
+			}
+			o = preset
+			continue
+		}
+		weight, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return Objective{}, fmt.Errorf("invalid weight of %s: %w", name, err)
+		}
+		i := len(measures)
+		for j := range measures {
+			if measures[j].name == name {
+				i = j
+				break
+			}
+		}
+		if i == len(measures) {
+			names := make([]string, len(measures))
+			for j, m := range measures {
+				names[j] = m.name
+			}
+			return Objective{}, fmt.Errorf("unknown objective %q, use one of %s", name, strings.Join(names, ", "))
+		}
+		*measures[i].weight(&o) = weight
+	}
+	return o, nil
+}
+
+// Score returns the score of the layout of the cells.
+func (o Objective) Score(cells [][]*board.Cell) float64 {
+	return o.Breakdown(cells).Score()
+}
+
+// Breakdown returns the terms of the score of the layout of the cells, one
+// per measure. All measures are zero for a layout without letters.
+func (o Objective) Breakdown(cells [][]*board.Cell) Breakdown {
+	l, ok := measure(cells)
+	terms := make(Breakdown, len(measures))
+	for i, m := range measures {
+		terms[i] = Term{Name: m.name, Weight: *m.weight(&o)}
+		if ok {
+			terms[i].Value = m.value(l)
+		}
+	}
+	return terms
+}
+
+// wordsOnly reports whether the objective weighs nothing but the words, so
+// a layout with all words cannot be improved.
+func (o Objective) wordsOnly() bool {
+	return o == Objective{Words: o.Words}
+}
+
+// Term is the part one measure adds to the score of a layout.
+type Term struct {
+	Name   string  `json:"name"`
+	Value  float64 `json:"value"`  // The measure of the layout.
+	Weight float64 `json:"weight"` // The weight of the measure in the objective.
+}
+
+// Breakdown lists the terms of the score of a layout.
+type Breakdown []Term
+
+// Score returns the score of the layout, the sum of its terms.
+func (b Breakdown) Score() float64 {
+	score := 0.0
+	for _, t := range b {
+		score += t.Value * t.Weight
+	}
+	return score
+}
+
+// String shows the terms with a weight and the score, like
+// "words 18 × 1 + intersections 20 × 0.01 = 18.2".
+func (b Breakdown) String() string {
+	var terms []string
+	for _, t := range b {
+		if t.Weight != 0 {
+			terms = append(terms, fmt.Sprintf("%s %.3g × %g", t.Name, t.Value, t.Weight))
+		}
+	}
+	return fmt.Sprintf("%s = %.4g", strings.Join(terms, " + "), b.Score())
+}
+
+// layoutStats holds what the measures of a layout are computed from.
+type layoutStats struct {
+	words, intersections, letters int
+	cells                         int // The number of cells of the board.
+	boxWidth, boxHeight           int // The size of the smallest rectangle around the letters.
+	spread                        float64
+}
+
+// measure returns the stats of the layout of the cells, and false if it
+// has no letters.
+func measure(cells [][]*board.Cell) (layoutStats, bool) {
+	if len(cells) == 0 {
+		return layoutStats{}, false
+	}
+	l := layoutStats{cells: len(cells) * len(cells[0])}
+	minX, minY, maxX, maxY := len(cells[0]), len(cells), -1, -1
+	for y, row := range cells {
+		for x, cell := range row {
+			if !cell.Filled {
+				continue
+			}
+			l.letters++
+			if cell.UsageCount > 1 {
+				l.intersections++
+			}
+			minX, minY = min(minX, x), min(minY, y)
+			maxX, maxY = max(maxX, x), max(maxY, y)
+		}
+	}
+	if maxX < 0 {
+		return layoutStats{}, false
+	}
+	l.words = countWords(cells)
+	l.boxWidth, l.boxHeight = maxX-minX+1, maxY-minY+1
+
+	// The spread is the entropy of the letters over the nine parts of the
+	// rectangle, relative to that of letters spread evenly.
+	var parts [9]int
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			if cells[y][x].Filled {
+				parts[(y-minY)*3/l.boxHeight*3+(x-minX)*3/l.boxWidth]++
+			}
+		}
+	}
+	for _, n := range parts {
+		if n > 0 {
+			p := float64(n) / float64(l.letters)
+			l.spread -= p * math.Log(p) / math.Log(9)
+		}
+	}
+	return l, true
+}
+
+// countWords returns the number of runs of at least two letters in both
+// directions, which are the words of a valid layout.
+func countWords(cells [][]*board.Cell) int {
+	filled := func(x, y int) bool {
+		return y >= 0 && y < len(cells) && x >= 0 && x < len(cells[y]) && cells[y][x].Filled
+	}
+	n := 0
+	for y, row := range cells {
+		for x := range row {
+			if !filled(x, y) {
+				continue
+			}
+			if !filled(x-1, y) && filled(x+1, y) {
+				n++
+			}
+			if !filled(x, y-1) && filled(x, y+1) {
+				n++
+			}
+		}
+	}
+	return n
+}
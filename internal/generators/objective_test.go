@@ -0,0 +1,122 @@
+package generators_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/generators"
+)
+
+// crossingBoard returns a 10 by 10 board with two words crossing once in a
+// 4 by 3 box.
+func crossingBoard(t *testing.T) *board.Board {
+	t.Helper()
+	bounds, err := board.NewBoundsRectangle(10, 10)
+	if err != nil {
+		t.Fatalf("Failed to create bounds: %s", err)
+	}
+	b := board.NewBoard(bounds, 2, &board.OSFileWriter{})
+	b.PlaceWordAt(board.Location{X: 0, Y: 1}, "haus", board.Across)
+	b.PlaceWordAt(board.Location{X: 1, Y: 0}, "bad", board.Down)
+	return b
+}
+
+func TestObjective_Score(t *testing.T) {
+	b := crossingBoard(t)
+
+	tests := []struct {
+		name      string
+		objective generators.Objective
+		want      float64
+	}{
+		{"words", generators.Objective{Words: 1}, 2},
+		{"intersections", generators.Objective{Intersections: 2}, 2},
+		{"compactness", generators.Objective{Compactness: 1}, 0.88},
+		{"interlock", generators.Objective{Interlock: 1}, 0.5},
+		{"checked", generators.Objective{Checked: 6}, 1},
+		{"density", generators.Objective{Density: 1}, 0.5},
+		{"aspect", generators.Objective{Aspect: 1}, 0.75},
+		// Six letters in five of the nine parts, two of them in one.
+		{"spread", generators.Objective{Spread: 1}, 0.7103099},
+		{"negative", generators.Objective{Words: 1, Density: -1}, 1.5},
+		{"all", generators.Objective{Words: 1, Intersections: 0.5, Compactness: 1}, 3.38},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.objective.Score(b.Cells); math.Abs(got-tt.want) > 1e-6 {
+				t.Errorf("Incorrect score, got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestObjective_Breakdown(t *testing.T) {
+	b := crossingBoard(t)
+	o := generators.Objective{Words: 1, Intersections: 0.5, Density: -1}
+
+	got := o.Breakdown(b.Cells)
+	if got.Score() != o.Score(b.Cells) {
+		t.Errorf("Incorrect score of the breakdown, got: %v, want: %v", got.Score(), o.Score(b.Cells))
+	}
+	want := "words 2 × 1 + intersections 1 × 0.5 + density 0.5 × -1 = 2"
+	if got.String() != want {
+		t.Errorf("Incorrect breakdown, got: %q, want: %q", got.String(), want)
+	}
+
+	empty, _ := board.NewBoundsRectangle(3, 3)
+	if got := o.Breakdown(board.NewBoard(empty, 0, &board.OSFileWriter{}).Cells).Score(); got != 0 {
+		t.Errorf("Incorrect score of an empty board, got: %v, want: 0", got)
+	}
+}
+
+func TestObjectives(t *testing.T) {
+	b := crossingBoard(t)
+	compact, sparse := generators.Objectives["compact"], generators.Objectives["sparse"]
+
+	// A sparser layout of the same words: the two words apart.
+	bounds, _ := board.NewBoundsRectangle(10, 10)
+	apart := board.NewBoard(bounds, 2, &board.OSFileWriter{})
+	apart.PlaceWordAt(board.Location{X: 0, Y: 0}, "haus", board.Across)
+	apart.PlaceWordAt(board.Location{X: 9, Y: 5}, "bad", board.Down)
+
+	if compact.Score(b.Cells) <= compact.Score(apart.Cells) {
+		t.Errorf("Expected the compact objective to prefer crossing words, got: %v, apart: %v", compact.Score(b.Cells), compact.Score(apart.Cells))
+	}
+	if sparse.Score(b.Cells) >= sparse.Score(apart.Cells) {
+		t.Errorf("Expected the sparse objective to prefer words apart, got: %v, crossing: %v", sparse.Score(apart.Cells), sparse.Score(b.Cells))
+	}
+	for _, name := range generators.ObjectiveNames {
+		if _, ok := generators.Objectives[name]; !ok {
+			t.Errorf("Missing objective %q", name)
+		}
+	}
+}
+
+func TestParseObjective(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    generators.Objective
+		wantErr bool
+	}{
+		{in: "words=1", want: generators.Objective{Words: 1}},
+		{in: "words=1, intersections=0.5,compactness=2", want: generators.Objective{Words: 1, Intersections: 0.5, Compactness: 2}},
+		{in: "words=1,interlock=0.2,checked=0.1,density=-0.3,aspect=1,spread=0.5", want: generators.Objective{Words: 1, Interlock: 0.2, Checked: 0.1, Density: -0.3, Aspect: 1, Spread: 0.5}},
+		{in: "compact", want: generators.Objectives["compact"]},
+		{in: "sparse,words=2", want: generators.Objective{Words: 2, Checked: -0.3, Density: -0.3, Spread: 0.3}},
+		{in: "words", wantErr: true},
+		{in: "words=many", wantErr: true},
+		{in: "beauty=1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := generators.ParseObjective(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Incorrect error, got: %v, want error: %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Incorrect objective, got: %+v, want: %+v", got, tt.want)
+			}
+		})
+	}
+}
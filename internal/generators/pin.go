@@ -0,0 +1,46 @@
+package generators
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+)
+
+// ErrInvalidPin is returned for pinned words that cannot be placed where
+// they are pinned, or by generators that cannot place pinned words.
+var ErrInvalidPin = errors.New("invalid pinned word")
+
+// placePinned places the pinned words on the empty board b in the order
+// given, and returns the entries left to place. A pinned word takes the
+// place of the first entry with the same word, and its hint if it has none
+// of its own; pinned words not among the entries are placed as well. Unlike
+// the other words, pinned words do not have to cross a letter.
+func placePinned(b *board.Board, entries []words.Entry, pinned []board.PlacedWord) ([]words.Entry, error) {
+	taken := make([]bool, len(entries))
+	for _, p := range pinned {
+		if !b.CanPinWordAt(p.Start, p.Word, p.Direction) {
+			return nil, fmt.Errorf("%w: %q does not fit at (%d, %d)", ErrInvalidPin, p.Word, p.Start.X, p.Start.Y)
+		}
+		entry := words.Entry{Word: p.Word, Hint: p.Hint}
+		for i, e := range entries {
+			if !taken[i] && e.Word == p.Word {
+				taken[i] = true
+				if entry.Hint == "" {
+					entry.Hint = e.Hint
+				}
+				break
+			}
+		}
+		b.PlaceEntryAt(p.Start, entry, p.Direction)
+	}
+
+	rest := make([]words.Entry, 0, len(entries))
+	for i, e := range entries {
+		if !taken[i] {
+			rest = append(rest, e)
+		}
+	}
+	return rest, nil
+}
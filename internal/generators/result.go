@@ -0,0 +1,78 @@
+package generators
+
+import (
+	"context"
+	"errors"
+)
+
+// DefaultMaxBacktracks is the backtrack budget of a generation run unless
+// another one is configured.
+const DefaultMaxBacktracks = 50000
+
+// Reason tells why a generator stopped.
+type Reason int
+
+const (
+	// Complete (0) means all words were placed.
+	Complete Reason = iota
+	// Exhausted (1) means every placement was tried without placing all
+	// words.
+	Exhausted
+	// Canceled (2) means the context was canceled.
+	Canceled
+	// DeadlineExceeded (3) means the deadline of the context passed.
+	DeadlineExceeded
+	// BudgetExceeded (4) means the node or backtrack budget ran out.
+	BudgetExceeded
+)
+
+// String returns the name of the reason as used in logs and the API.
+func (r Reason) String() string {
+	switch r {
+	case Complete:
+		return "complete"
+	case Exhausted:
+		return "exhausted"
+	case Canceled:
+		return "canceled"
+	case DeadlineExceeded:
+		return "deadline_exceeded"
+	case BudgetExceeded:
+		return "budget_exceeded"
+	}
+	return "unknown"
+}
+
+// Stopped reports whether the search was cut short from the outside, so
+// trying again is pointless.
+func (r Reason) Stopped() bool {
+	return r == Canceled || r == DeadlineExceeded
+}
+
+// Budget limits the work of a single generation run. Zero fields mean no
+// limit.
+type Budget struct {
+	MaxNodes      int // The max number of word placements tried.
+	MaxBacktracks int // The max number of placements taken back.
+}
+
+// Result describes how a generation run ended. The best board found, be it
+// complete or partial, is saved as the best solution of the board.
+type Result struct {
+	Reason     Reason
+	Placed     int // The number of words in the best solution.
+	Nodes      int // The number of word placements tried.
+	Backtracks int // The number of placements taken back.
+}
+
+// errStopped unwinds the search when it has to stop early.
+var errStopped = errors.New("generation stopped")
+
+// ContextReason returns the reason a run stopped for the error of its done
+// context.
+func ContextReason(err error) Reason {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return DeadlineExceeded
+	}
+	return Canceled
+}
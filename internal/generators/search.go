@@ -0,0 +1,203 @@
+package generators
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+)
+
+// run holds the state of a single call to Generate, so that generators keep
+// no state between runs and share none with each other.
+type run struct {
+	ctx        context.Context
+	budget     Budget
+	nodes      int    // Placements tried in the current run.
+	backtracks int    // Placements taken back in the current run.
+	stop       Reason // Why the current run stopped early.
+	complete   bool   // Whether a layout with all words was found.
+}
+
+// start resets the run for a new call to Generate.
+func (r *run) start(ctx context.Context, budget Budget) {
+	*r = run{ctx: ctx, budget: budget}
+}
+
+// shouldStop reports whether the run has to stop and records why.
+func (r *run) shouldStop() bool {
+	switch {
+	case r.ctx.Err() != nil:
+		r.stop = ContextReason(r.ctx.Err())
+	case r.budget.MaxNodes > 0 && r.nodes >= r.budget.MaxNodes:
+		r.stop = BudgetExceeded
+	case r.budget.MaxBacktracks > 0 && r.backtracks >= r.budget.MaxBacktracks:
+		r.stop = BudgetExceeded
+	default:
+		return false
+	}
+	return true
+}
+
+// errKeepLooking is returned by a search that placed all words but goes
+// on for a layout with a higher score.
+var errKeepLooking = errors.New("all words placed, looking for a better layout")
+
+// found records a layout with all words and reports whether the search is
+// done. It goes on for a layout with a higher score as long as the budget
+// has a limit and the objective weighs more than the words.
+func (r *run) found(o Objective) bool {
+	r.complete = true
+	return o.wordsOnly() || (r.budget.MaxNodes <= 0 && r.budget.MaxBacktracks <= 0)
+}
+
+// result returns the result of the run for the board.
+func (r *run) result(b *board.Board, reason Reason) Result {
+	return Result{
+		Reason:     reason,
+		Placed:     b.BestWordCount,
+		Nodes:      r.nodes,
+		Backtracks: r.backtracks,
+	}
+}
+
+// debugf prints the trace message if board.Debug is on.
+func debugf(format string, a ...any) {
+	if board.Debug {
+		fmt.Printf(format, a...)
+	}
+}
+
+// shuffleTies shuffles runs of entries of equal length, so words are still
+// placed from the longest to the shortest.
+func shuffleTies(rng *rand.Rand, entries []words.Entry) {
+	for start := 0; start < len(entries); {
+		end := start + 1
+		for end < len(entries) && entries[end].Length() == entries[start].Length() {
+			end++
+		}
+		run := entries[start:end]
+		rng.Shuffle(len(run), func(i, j int) { run[i], run[j] = run[j], run[i] })
+		start = end
+	}
+}
+
+// pickFirstWord moves a random word of the longest quarter of the entries
+// to the front, so attempts do not all grow from the same word.
+func pickFirstWord(rng *rand.Rand, entries []words.Entry) {
+	if len(entries) == 0 {
+		return
+	}
+	i := rng.Intn((len(entries) + 3) / 4)
+	entries[0], entries[i] = entries[i], entries[0]
+}
+
+// errFirstWordTooLong is returned when the first word does not fit on the
+// board.
+var errFirstWordTooLong = errors.New("first word does not fit on the board")
+
+// placeFirstWord places the entry across the middle of the board. If the
+// mask of the board leaves no room there, it takes the spot closest to the
+// middle where the word fits.
+func placeFirstWord(b *board.Board, first words.Entry) error {
+	midRow := b.Bounds.Height() / 2
+	startCol := (b.Bounds.Width() - first.Length()) / 2
+	start := board.Location{X: startCol, Y: midRow}
+	if !b.Fits(start, first.Word, board.Across) {
+		if b.Mask == nil {
+			return errFirstWordTooLong
+		}
+		var spots []board.Location
+		for y := 0; y < b.Bounds.Height(); y++ {
+			for x := 0; x+first.Length() <= b.Bounds.Width(); x++ {
+				if l := (board.Location{X: x, Y: y}); b.Fits(l, first.Word, board.Across) {
+					spots = append(spots, l)
+				}
+			}
+		}
+		if len(spots) == 0 {
+			return errFirstWordTooLong
+		}
+		distance := func(l board.Location) int { return abs(l.Y-midRow)*b.Bounds.Width() + abs(l.X-startCol) }
+		sort.SliceStable(spots, func(i, j int) bool { return distance(spots[i]) < distance(spots[j]) })
+		start = spots[0]
+	}
+
+	err := b.PlaceEntryAt(start, first, board.Across)
+	if err != nil {
+		return fmt.Errorf("failed to place the first word: %w", err)
+	}
+	return nil
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// saveIfBetter saves the board as the best solution if it holds more words
+// than the best solution so far, or as many with a higher score by the
+// objective, and reports whether it did.
+func saveIfBetter(b *board.Board, o Objective) bool {
+	better := b.WordCount > b.BestWordCount
+	if !better && b.WordCount > 0 && b.WordCount == b.BestWordCount && b.BestBoard != nil {
+		better = o.Score(b.Cells) > o.Score(b.BestBoard)
+	}
+	if better {
+		b.SaveBestSolution()
+		return true
+	}
+	return false
+}
+
+// restoreBest places the words of the best solution on the empty board.
+func restoreBest(b *board.Board) {
+	for _, p := range b.BestPlacedWords {
+		b.PlaceEntryAt(p.Start, words.Entry{Word: p.Word, Hint: p.Hint}, p.Direction)
+	}
+}
+
+// clearBoard removes all words from the board, from the last word placed
+// to the first.
+func clearBoard(b *board.Board) {
+	for len(b.PlacedWords) > 0 {
+		last := b.PlacedWords[len(b.PlacedWords)-1]
+		b.RemoveWord(last.Start, last.Word, last.Direction)
+	}
+}
+
+type Placement struct {
+	Start     board.Location
+	Direction board.Direction
+}
+
+// findPlacements returns all locations where the word can be placed on the
+// board, row by row.
+func findPlacements(b *board.Board, word string) []Placement {
+	var placements []Placement
+
+	// Helper function to try placing a word in one direction
+	tryPlaceWord := func(x, y int, dir board.Direction) {
+		if b.CanPlaceWordAt(board.Location{X: x, Y: y}, word, dir) {
+			placements = append(placements, Placement{
+				Start:     board.Location{X: x, Y: y},
+				Direction: dir,
+			})
+		}
+	}
+
+	// Iterate over each cell in the board
+	for y := 0; y < len(b.Cells); y++ {
+		for x := 0; x < len(b.Cells[y]); x++ {
+			tryPlaceWord(x, y, board.Across) // Try horizontal placement
+			tryPlaceWord(x, y, board.Down)   // Try vertical placement
+		}
+	}
+	return placements
+}
@@ -0,0 +1,119 @@
+package generators
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+)
+
+// Symmetry is a symmetry the layout of a free-form board should follow.
+type Symmetry string
+
+// The symmetries of free-form boards. An empty Symmetry means NoSymmetry.
+const (
+	NoSymmetry Symmetry = "none"       // Words go wherever they fit.
+	LeftRight  Symmetry = "left-right" // The layout mirrors at the vertical middle line.
+	TopBottom  Symmetry = "top-bottom" // The layout mirrors at the horizontal middle line.
+	Diagonal   Symmetry = "diagonal"   // The layout mirrors at the diagonal from the top left; needs a square board.
+)
+
+// SymmetryNames lists the names of the available symmetries.
+var SymmetryNames = []string{string(NoSymmetry), string(LeftRight), string(TopBottom), string(Diagonal)}
+
+// ErrUnknownSymmetry is returned by New for symmetries it does not know.
+var ErrUnknownSymmetry = errors.New("unknown symmetry")
+
+// ErrInvalidSymmetry is returned by New for symmetries the generator or the
+// board cannot follow.
+var ErrInvalidSymmetry = errors.New("invalid symmetry")
+
+// errNotSquare is returned when a symmetry needs a square board.
+var errNotSquare = fmt.Errorf("%w: %s symmetry needs a square board", ErrInvalidSymmetry, Diagonal)
+
+// check returns an error if the symmetry is unknown.
+func (s Symmetry) check() error {
+	if s == "" {
+		return nil
+	}
+	for _, name := range SymmetryNames {
+		if string(s) == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w %q", ErrUnknownSymmetry, string(s))
+}
+
+// checkBoard returns an error if the board cannot follow the symmetry.
+func (s Symmetry) checkBoard(b *board.Board) error {
+	if s == Diagonal && b.Bounds.Width() != b.Bounds.Height() {
+		return errNotSquare
+	}
+	return nil
+}
+
+// none reports whether the symmetry leaves the layout free.
+func (s Symmetry) none() bool {
+	return s == "" || s == NoSymmetry
+}
+
+// mirrorCell returns the cell x, y turns into on a w by h board.
+func (s Symmetry) mirrorCell(w, h, x, y int) (int, int) {
+	switch s {
+	case LeftRight:
+		return w - 1 - x, y
+	case TopBottom:
+		return x, h - 1 - y
+	case Diagonal:
+		return y, x
+	}
+	return x, y
+}
+
+// mirror returns the placement of a word of n letters at p turns into on a
+// w by h board. The start of the mirrored word is its first letter again,
+// so mirroring a placement across the direction of the word moves its start
+// to the other end.
+func (s Symmetry) mirror(w, h int, p Placement, n int) Placement {
+	dx, dy := deltas(p.Direction)
+	endX, endY := p.Start.X+(n-1)*dx, p.Start.Y+(n-1)*dy
+	x1, y1 := s.mirrorCell(w, h, p.Start.X, p.Start.Y)
+	x2, y2 := s.mirrorCell(w, h, endX, endY)
+	dir := p.Direction
+	if s == Diagonal {
+		dir = board.Down
+		if p.Direction == board.Down {
+			dir = board.Across
+		}
+	}
+	return Placement{Start: board.Location{X: min(x1, x2), Y: min(y1, y2)}, Direction: dir}
+}
+
+// SymmetryScore returns how symmetric the best solution of the board is: the
+// share of its letter cells whose mirror cell holds a letter too. A board
+// that follows the symmetry perfectly scores 1, as does an empty board or
+// one without a symmetry to follow.
+func SymmetryScore(b *board.Board, s Symmetry) float64 {
+	if s.none() || b.BestBoard == nil {
+		return 1
+	}
+	h := len(b.BestBoard)
+	w := len(b.BestBoard[0])
+	letters, matched := 0, 0
+	for y, row := range b.BestBoard {
+		for x, cell := range row {
+			if !cell.Filled {
+				continue
+			}
+			letters++
+			mx, my := s.mirrorCell(w, h, x, y)
+			if mx < w && my < h && b.BestBoard[my][mx].Filled {
+				matched++
+			}
+		}
+	}
+	if letters == 0 {
+		return 1
+	}
+	return float64(matched) / float64(letters)
+}
@@ -0,0 +1,125 @@
+package generators_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/generators"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+)
+
+func TestSymmetryScore(t *testing.T) {
+	bounds, err := board.NewBoundsRectangle(7, 7)
+	if err != nil {
+		t.Fatalf("Failed to create bounds: %s", err)
+	}
+	b := board.NewBoard(bounds, 2, &board.OSFileWriter{})
+	b.PlaceWordAt(board.Location{X: 1, Y: 3}, "hello", board.Across)
+	b.PlaceWordAt(board.Location{X: 5, Y: 2}, "ton", board.Down)
+	b.SaveBestSolution()
+
+	tests := []struct {
+		symmetry generators.Symmetry
+		want     float64
+	}{
+		{"", 1},
+		{generators.NoSymmetry, 1},
+		{generators.LeftRight, 5.0 / 7},
+		{generators.TopBottom, 1},
+		{generators.Diagonal, 1.0 / 7},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.symmetry), func(t *testing.T) {
+			if got := generators.SymmetryScore(b, tt.symmetry); got != tt.want {
+				t.Errorf("Incorrect score, got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAsymmetricalGenerator_Symmetry(t *testing.T) {
+	// "lhz" crosses "hello" either down through its h, the first placement
+	// row by row, or down from its middle l, mirroring "hello" at the
+	// diagonal.
+	tests := []struct {
+		name      string
+		symmetry  generators.Symmetry
+		wantStart board.Location
+		wantScore float64
+	}{
+		{"none", generators.NoSymmetry, board.Location{X: 1, Y: 2}, 1.0 / 7},
+		{"diagonal", generators.Diagonal, board.Location{X: 3, Y: 3}, 5.0 / 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bounds, err := board.NewBoundsRectangle(7, 7)
+			if err != nil {
+				t.Fatalf("Failed to create bounds: %s", err)
+			}
+			b := board.NewBoard(bounds, 2, &board.OSFileWriter{})
+			pool := words.NewPool()
+			pool.LoadWords([]string{"hello", "lhz"})
+			g, err := generators.New(generators.Asymmetrical, b, pool, generators.Options{Symmetry: tt.symmetry})
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			res, err := g.Generate(context.Background())
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if res.Reason != generators.Complete {
+				t.Fatalf("Incorrect reason, got: %s, want: %s", res.Reason, generators.Complete)
+			}
+			if got := b.BestPlacedWords[1].Start; got != tt.wantStart {
+				t.Errorf("Incorrect start of lhz, got: %v, want: %v", got, tt.wantStart)
+			}
+			if got := generators.SymmetryScore(b, generators.Diagonal); got != tt.wantScore {
+				t.Errorf("Incorrect score, got: %v, want: %v", got, tt.wantScore)
+			}
+		})
+	}
+}
+
+func TestAsymmetricalGenerator_SymmetryErrors(t *testing.T) {
+	pool := words.NewPool()
+	pool.LoadWords([]string{"hello", "lhz"})
+
+	bounds, err := board.NewBoundsRectangle(7, 7)
+	if err != nil {
+		t.Fatalf("Failed to create bounds: %s", err)
+	}
+	b := board.NewBoard(bounds, 2, &board.OSFileWriter{})
+	_, err = generators.New(generators.Asymmetrical, b, pool, generators.Options{Symmetry: "spiral"})
+	if !errors.Is(err, generators.ErrUnknownSymmetry) {
+		t.Errorf("Incorrect error, got: %v, want: %v", err, generators.ErrUnknownSymmetry)
+	}
+
+	bounds, err = board.NewBoundsRectangle(9, 7)
+	if err != nil {
+		t.Fatalf("Failed to create bounds: %s", err)
+	}
+	b = board.NewBoard(bounds, 2, &board.OSFileWriter{})
+	_, err = generators.New(generators.Asymmetrical, b, pool, generators.Options{Symmetry: generators.Diagonal})
+	if !errors.Is(err, generators.ErrInvalidSymmetry) {
+		t.Errorf("Incorrect error for a board that is not square, got: %v, want: %v", err, generators.ErrInvalidSymmetry)
+	}
+	g := generators.NewAsymmetricalGenerator(b, pool, nil)
+	g.Symmetry = generators.Diagonal
+	if _, err := g.Generate(context.Background()); !errors.Is(err, generators.ErrInvalidSymmetry) {
+		t.Errorf("Incorrect error of Generate for a board that is not square, got: %v, want: %v", err, generators.ErrInvalidSymmetry)
+	}
+
+	// Only the asymmetrical generator follows a symmetry.
+	for _, name := range generators.Names {
+		_, err := generators.New(name, b, pool, generators.Options{Symmetry: generators.LeftRight})
+		if wantErr := name != generators.Asymmetrical; errors.Is(err, generators.ErrInvalidSymmetry) != wantErr {
+			t.Errorf("Incorrect error for the %s generator, got: %v, want error: %v", name, err, wantErr)
+		}
+		if _, err := generators.New(name, b, pool, generators.Options{Symmetry: generators.NoSymmetry}); err != nil {
+			t.Errorf("Unexpected error for the %s generator without symmetry: %s", name, err)
+		}
+	}
+}
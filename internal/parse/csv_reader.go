@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/Germanicus1/crizzcrozz/internal/models"
+	"github.com/Germanicus1/crizzcrozz/pkg/models"
 	"github.com/gocarina/gocsv"
 )
 
@@ -4,8 +4,8 @@ import (
 	"os"
 	"testing"
 
-	"github.com/Germanicus1/crizzcrozz/internal/models"
 	"github.com/Germanicus1/crizzcrozz/internal/parse"
+	"github.com/Germanicus1/crizzcrozz/pkg/models"
 )
 
 // Utility function to create a mock CSV file for testing.
@@ -1 +1,50 @@
 package parse
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+	"github.com/Germanicus1/crizzcrozz/pkg/models"
+)
+
+// CleanWords returns the words of wh and their hints without surrounding
+// white space.
+func CleanWords(wh []*models.WordsAndHints) []words.Entry {
+	var entries []words.Entry
+	for _, v := range wh {
+		entries = append(entries, words.Entry{
+			Word: strings.TrimSpace(v.Word),
+			Hint: strings.TrimSpace(v.Hint),
+		})
+	}
+	return entries
+}
+
+// CleanPinnedWords returns the pinned words of pw as placed words, with
+// their words and hints cleaned like CleanWords does. Pinned words have to
+// start on a board, at coordinates of 0 or more.
+func CleanPinnedWords(pw []*models.PinnedWord) ([]board.PlacedWord, error) {
+	var pinned []board.PlacedWord
+	for _, v := range pw {
+		word := strings.TrimSpace(v.Word)
+		if word == "" {
+			return nil, fmt.Errorf("word %d is empty", len(pinned)+1)
+		}
+		if v.X < 0 || v.Y < 0 {
+			return nil, fmt.Errorf("%q starts off the board at (%d, %d)", word, v.X, v.Y)
+		}
+		direction, err := board.ParseDirection(v.Direction)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", word, err)
+		}
+		pinned = append(pinned, board.PlacedWord{
+			Start:     board.Location{X: v.X, Y: v.Y},
+			Direction: direction,
+			Word:      word,
+			Hint:      strings.TrimSpace(v.Hint),
+		})
+	}
+	return pinned, nil
+}
@@ -0,0 +1,334 @@
+package puzzle
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+)
+
+const (
+	ipuzVersion    = "http://ipuz.org/v2"
+	ipuzKind       = "http://ipuz.org/crossword#1"
+	ipuzKindPrefix = "http://ipuz.org/crossword"
+	ipuzBlock      = "#"
+	ipuzEmpty      = 0
+)
+
+// ErrInvalidIPuz is returned when an ipuz document cannot be read as a
+// crossword. The wrapping error names the offending field.
+var ErrInvalidIPuz = errors.New("invalid ipuz crossword")
+
+// ipuzDocument is the subset of the ipuz format (http://ipuz.org) used for
+// crosswords. Cells and clues may take several shapes, so they are decoded
+// in a second step.
+type ipuzDocument struct {
+	Version    string                       `json:"version"`
+	Kind       []string                     `json:"kind"`
+	Title      string                       `json:"title,omitempty"`
+	Author     string                       `json:"author,omitempty"`
+	Copyright  string                       `json:"copyright,omitempty"`
+	Notes      string                       `json:"notes,omitempty"`
+	Dimensions ipuzDimensions               `json:"dimensions"`
+	Block      json.RawMessage              `json:"block,omitempty"`
+	Empty      json.RawMessage              `json:"empty,omitempty"`
+	Puzzle     [][]json.RawMessage          `json:"puzzle"`
+	Solution   [][]json.RawMessage          `json:"solution"`
+	Clues      map[string][]json.RawMessage `json:"clues"`
+}
+
+type ipuzDimensions struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// WriteIPuz writes the puzzle as an ipuz crossword document.
+func (p *Puzzle) WriteIPuz(w io.Writer) error {
+	doc := ipuzDocument{
+		Version:    ipuzVersion,
+		Kind:       []string{ipuzKind},
+		Title:      p.Title,
+		Author:     p.Author,
+		Copyright:  p.Copyright,
+		Notes:      p.Notes,
+		Dimensions: ipuzDimensions{Width: p.Width, Height: p.Height},
+		Block:      json.RawMessage(strconv.Quote(ipuzBlock)),
+		Empty:      json.RawMessage(strconv.Itoa(ipuzEmpty)),
+		Puzzle:     make([][]json.RawMessage, p.Height),
+		Solution:   make([][]json.RawMessage, p.Height),
+		Clues: map[string][]json.RawMessage{
+			"Across": ipuzClues(p.Across),
+			"Down":   ipuzClues(p.Down),
+		},
+	}
+	for y, row := range p.Grid {
+		doc.Puzzle[y] = make([]json.RawMessage, len(row))
+		doc.Solution[y] = make([]json.RawMessage, len(row))
+		for x, cell := range row {
+			switch {
+			case cell.Block:
+				doc.Puzzle[y][x] = doc.Block
+				doc.Solution[y][x] = doc.Block
+				continue
+			case cell.Number > 0:
+				doc.Puzzle[y][x] = json.RawMessage(strconv.Itoa(cell.Number))
+			default:
+				doc.Puzzle[y][x] = doc.Empty
+			}
+			letter, err := json.Marshal(cell.Letter)
+			if err != nil {
+				return err
+			}
+			doc.Solution[y][x] = letter
+		}
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// ipuzClues encodes entries as [number, clue] pairs.
+func ipuzClues(entries []Entry) []json.RawMessage {
+	clues := make([]json.RawMessage, 0, len(entries))
+	for _, e := range entries {
+		clue, _ := json.Marshal([]any{e.Number, e.Hint})
+		clues = append(clues, clue)
+	}
+	return clues
+}
+
+// ReadIPuz reads an ipuz crossword document. The document must contain a
+// solution for every cell that is not a block. The grid is numbered again
+// the standard way; clues are matched to entries through the cell labels of
+// the document, or by number when the document has no labels.
+func ReadIPuz(r io.Reader) (*Puzzle, error) {
+	var doc ipuzDocument
+	dec := json.NewDecoder(r)
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidIPuz, err)
+	}
+
+	if !strings.HasPrefix(doc.Version, "http://ipuz.org/v") {
+		return nil, fmt.Errorf("%w: unknown version %q", ErrInvalidIPuz, doc.Version)
+	}
+	isCrossword := false
+	for _, kind := range doc.Kind {
+		if strings.HasPrefix(kind, ipuzKindPrefix) {
+			isCrossword = true
+		}
+	}
+	if !isCrossword {
+		return nil, fmt.Errorf("%w: kind %v is not a crossword", ErrInvalidIPuz, doc.Kind)
+	}
+
+	width, height := doc.Dimensions.Width, doc.Dimensions.Height
+	if width < 1 || height < 1 {
+		return nil, fmt.Errorf("%w: dimensions must be positive, got %dx%d", ErrInvalidIPuz, width, height)
+	}
+	if len(doc.Solution) == 0 {
+		return nil, fmt.Errorf("%w: solution is required", ErrInvalidIPuz)
+	}
+	if err := checkIPuzGrid("solution", doc.Solution, width, height); err != nil {
+		return nil, err
+	}
+	if len(doc.Puzzle) > 0 {
+		if err := checkIPuzGrid("puzzle", doc.Puzzle, width, height); err != nil {
+			return nil, err
+		}
+	}
+
+	block := ipuzBlock
+	if len(doc.Block) > 0 {
+		block = ipuzScalar(doc.Block)
+	}
+	empty := strconv.Itoa(ipuzEmpty)
+	if len(doc.Empty) > 0 {
+		empty = ipuzScalar(doc.Empty)
+	}
+
+	p := &Puzzle{
+		Version:   SchemaVersion,
+		Title:     doc.Title,
+		Author:    doc.Author,
+		Copyright: doc.Copyright,
+		Notes:     doc.Notes,
+		Width:     width,
+		Height:    height,
+		Grid:      make([][]Cell, height),
+	}
+	// labels maps the clue labels of the document to the cell they are in.
+	labels := make(map[string]board.Location)
+	for y := 0; y < height; y++ {
+		p.Grid[y] = make([]Cell, width)
+		for x := 0; x < width; x++ {
+			value, omitted := ipuzCellValue(doc.Solution[y][x], "value")
+			if omitted || value == block {
+				p.Grid[y][x] = Cell{Block: true}
+				continue
+			}
+			if len([]rune(value)) != 1 {
+				return nil, fmt.Errorf("%w: solution cell (%d, %d) must hold a single letter, got %q", ErrInvalidIPuz, x, y, value)
+			}
+			p.Grid[y][x] = Cell{Letter: strings.ToUpper(value)}
+
+			if len(doc.Puzzle) == 0 {
+				continue
+			}
+			label, omitted := ipuzCellValue(doc.Puzzle[y][x], "cell")
+			if omitted || label == block {
+				return nil, fmt.Errorf("%w: cell (%d, %d) is a block in the puzzle but not in the solution", ErrInvalidIPuz, x, y)
+			}
+			if label != empty && label != "" {
+				labels[label] = board.Location{X: x, Y: y}
+			}
+		}
+	}
+
+	p.setEntries(gridClues(p.Grid))
+
+	for key, clues := range doc.Clues {
+		direction, _, _ := strings.Cut(key, ":")
+		var entries []Entry
+		switch direction {
+		case "Across":
+			entries = p.Across
+		case "Down":
+			entries = p.Down
+		default:
+			continue // Diagonal and other clue directions have no entries in our grids.
+		}
+		for i, raw := range clues {
+			label, hint, err := ipuzClue(raw)
+			if err != nil {
+				return nil, fmt.Errorf("%w: clue %d in %q: %s", ErrInvalidIPuz, i+1, key, err)
+			}
+			entry := findIPuzEntry(entries, labels, label, i)
+			if entry == nil {
+				if label == "" {
+					return nil, fmt.Errorf("%w: clue %d in %q has no matching entry in the grid", ErrInvalidIPuz, i+1, key)
+				}
+				return nil, fmt.Errorf("%w: clue %q in %q has no matching entry in the grid", ErrInvalidIPuz, label, key)
+			}
+			entry.Hint = hint
+		}
+	}
+
+	return p, nil
+}
+
+// checkIPuzGrid returns an error unless grid has the given dimensions.
+func checkIPuzGrid(name string, grid [][]json.RawMessage, width, height int) error {
+	if len(grid) != height {
+		return fmt.Errorf("%w: %s has %d rows, want %d", ErrInvalidIPuz, name, len(grid), height)
+	}
+	for y, row := range grid {
+		if len(row) != width {
+			return fmt.Errorf("%w: %s row %d has %d cells, want %d", ErrInvalidIPuz, name, y, len(row), width)
+		}
+	}
+	return nil
+}
+
+// ipuzCellValue returns the value of a puzzle or solution cell as a string.
+// Cells may be plain numbers or strings, or objects holding the value under
+// key. Omitted (null) cells are reported as such.
+func ipuzCellValue(raw json.RawMessage, key string) (string, bool) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", true
+	}
+	var obj map[string]json.RawMessage
+	if json.Unmarshal(raw, &obj) == nil {
+		value, ok := obj[key]
+		if !ok {
+			return "", false
+		}
+		return ipuzCellValue(value, key)
+	}
+	return ipuzScalar(raw), false
+}
+
+// ipuzScalar returns a JSON string or number as a string.
+func ipuzScalar(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	return string(bytes.TrimSpace(raw))
+}
+
+// ipuzClue decodes a clue given as [number, "clue"], as an object with
+// "number" and "clue" keys, or as a plain string. Plain strings have no
+// label; they belong to the entry at their position in the clue list.
+func ipuzClue(raw json.RawMessage) (string, string, error) {
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return "", text, nil
+	}
+	var pair []json.RawMessage
+	if json.Unmarshal(raw, &pair) == nil {
+		if len(pair) != 2 {
+			return "", "", fmt.Errorf("want [number, clue], got %d elements", len(pair))
+		}
+		if err := json.Unmarshal(pair[1], &text); err != nil {
+			return "", "", fmt.Errorf("clue text must be a string")
+		}
+		return ipuzScalar(pair[0]), text, nil
+	}
+	var obj struct {
+		Number json.RawMessage `json:"number"`
+		Clue   string          `json:"clue"`
+	}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return "", "", fmt.Errorf("unknown clue format")
+	}
+	return ipuzScalar(obj.Number), obj.Clue, nil
+}
+
+// findIPuzEntry returns the entry the clue at index i with a label belongs
+// to. Labels found in the puzzle grid are matched by position, so documents
+// with their own numbering keep their clues; otherwise the label is taken
+// as a number. Clues without a label go with the entry at their index.
+func findIPuzEntry(entries []Entry, labels map[string]board.Location, label string, i int) *Entry {
+	if label == "" {
+		if i < len(entries) {
+			return &entries[i]
+		}
+		return nil
+	}
+	if loc, ok := labels[label]; ok {
+		for i := range entries {
+			if entries[i].X == loc.X && entries[i].Y == loc.Y {
+				return &entries[i]
+			}
+		}
+		return nil
+	}
+	number, err := strconv.Atoi(label)
+	if err != nil {
+		return nil
+	}
+	for i := range entries {
+		if entries[i].Number == number {
+			return &entries[i]
+		}
+	}
+	return nil
+}
+
+// SaveIPuz writes the ipuz encoding of the puzzle to the named file.
+func (p *Puzzle) SaveIPuz(fileName string, fw board.FileWriter) error {
+	var buf bytes.Buffer
+	if err := p.WriteIPuz(&buf); err != nil {
+		return err
+	}
+	return fw.WriteFile(fileName, buf.Bytes(), 0644)
+}
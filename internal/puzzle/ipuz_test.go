@@ -0,0 +1,188 @@
+package puzzle_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/puzzle"
+)
+
+func TestIPuzRoundTrip(t *testing.T) {
+	want := newTestPuzzle(t)
+
+	var buf bytes.Buffer
+	if err := want.WriteIPuz(&buf); err != nil {
+		t.Fatalf("Unexpected error writing ipuz: %s", err)
+	}
+	got, err := puzzle.ReadIPuz(&buf)
+	if err != nil {
+		t.Fatalf("Unexpected error reading ipuz: %s", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Incorrect round trip, got: %+v, want: %+v", got, want)
+	}
+}
+
+func TestReadIPuz_ForeignDocument(t *testing.T) {
+	// A document as other tools write it: cells as objects, its own block
+	// and empty markers, letter labels and clues as objects.
+	doc := `{
+		"version": "http://ipuz.org/v1",
+		"kind": ["http://ipuz.org/crossword"],
+		"dimensions": {"width": 3, "height": 3},
+		"block": "X",
+		"empty": "_",
+		"puzzle": [
+			[{"cell": "a", "style": {"shapebg": "circle"}}, "_", {"cell": "b"}],
+			["X", "X", "_"],
+			[null, "X", "_"]
+		],
+		"solution": [
+			["o", {"value": "h"}, "m"],
+			["X", "X", "a"],
+			[null, "X", "s"]
+		],
+		"clues": {
+			"Across": [{"number": "a", "clue": "Großmutter"}],
+			"Down:Senkrecht": [["b", "Ein Abstand"]]
+		}
+	}`
+
+	got, err := puzzle.ReadIPuz(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	wantAcross := []puzzle.Entry{{Number: 1, X: 0, Y: 0, Length: 3, Answer: "OHM", Hint: "Großmutter"}}
+	wantDown := []puzzle.Entry{{Number: 2, X: 2, Y: 0, Length: 3, Answer: "MAS", Hint: "Ein Abstand"}}
+	if !reflect.DeepEqual(got.Across, wantAcross) {
+		t.Errorf("Incorrect across entries, got: %+v, want: %+v", got.Across, wantAcross)
+	}
+	if !reflect.DeepEqual(got.Down, wantDown) {
+		t.Errorf("Incorrect down entries, got: %+v, want: %+v", got.Down, wantDown)
+	}
+	if !got.Grid[2][0].Block || got.Grid[0][2].Number != 2 {
+		t.Errorf("Incorrect grid, got: %+v", got.Grid)
+	}
+}
+
+func TestReadIPuz_PlainClues(t *testing.T) {
+	// Clues given as plain strings go with the entries in clue order.
+	doc := `{
+		"version": "http://ipuz.org/v2",
+		"kind": ["http://ipuz.org/crossword#1"],
+		"dimensions": {"width": 3, "height": 3},
+		"solution": [
+			["O", "H", "M"],
+			["#", "#", "A"],
+			["E", "I", "S"]
+		],
+		"clues": {
+			"Across": ["Einheit des Widerstands", "Gefrorenes Wasser"],
+			"Down": ["Ein Abstand"]
+		}
+	}`
+
+	got, err := puzzle.ReadIPuz(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	wantAcross := []string{"Einheit des Widerstands", "Gefrorenes Wasser"}
+	if len(got.Across) != len(wantAcross) {
+		t.Fatalf("Incorrect number of across entries, got: %d, want: %d", len(got.Across), len(wantAcross))
+	}
+	for i, want := range wantAcross {
+		if got.Across[i].Hint != want {
+			t.Errorf("Incorrect hint of across entry %d, got: %q, want: %q", i+1, got.Across[i].Hint, want)
+		}
+	}
+	if len(got.Down) != 1 || got.Down[0].Hint != "Ein Abstand" {
+		t.Errorf("Incorrect down entries, got: %+v", got.Down)
+	}
+}
+
+func TestReadIPuz_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{
+			name: "malformed json",
+			doc:  `{"version": `,
+			want: "unexpected EOF",
+		},
+		{
+			name: "not a crossword",
+			doc:  `{"version": "http://ipuz.org/v2", "kind": ["http://ipuz.org/sudoku#1"]}`,
+			want: "not a crossword",
+		},
+		{
+			name: "missing solution",
+			doc:  `{"version": "http://ipuz.org/v2", "kind": ["http://ipuz.org/crossword#1"], "dimensions": {"width": 1, "height": 1}}`,
+			want: "solution is required",
+		},
+		{
+			name: "wrong row count",
+			doc: `{"version": "http://ipuz.org/v2", "kind": ["http://ipuz.org/crossword#1"], "dimensions": {"width": 2, "height": 2},
+				"solution": [["A", "B"]]}`,
+			want: "solution has 1 rows, want 2",
+		},
+		{
+			name: "block mismatch",
+			doc: `{"version": "http://ipuz.org/v2", "kind": ["http://ipuz.org/crossword#1"], "dimensions": {"width": 2, "height": 1},
+				"puzzle": [[1, "#"]], "solution": [["A", "B"]]}`,
+			want: "cell (1, 0) is a block in the puzzle but not in the solution",
+		},
+		{
+			name: "clue without entry",
+			doc: `{"version": "http://ipuz.org/v2", "kind": ["http://ipuz.org/crossword#1"], "dimensions": {"width": 2, "height": 1},
+				"solution": [["A", "B"]], "clues": {"Down": [[1, "Nichts"]]}}`,
+			want: `clue "1" in "Down" has no matching entry`,
+		},
+		{
+			name: "more plain clues than entries",
+			doc: `{"version": "http://ipuz.org/v2", "kind": ["http://ipuz.org/crossword#1"], "dimensions": {"width": 2, "height": 1},
+				"solution": [["A", "B"]], "clues": {"Across": ["Erstes", "Zweites"]}}`,
+			want: `clue 2 in "Across" has no matching entry`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := puzzle.ReadIPuz(strings.NewReader(tt.doc))
+			if !errors.Is(err, puzzle.ErrInvalidIPuz) {
+				t.Fatalf("Expected ErrInvalidIPuz, got: %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Incorrect error, got: %q, want it to contain: %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestToBoard(t *testing.T) {
+	want := newTestPuzzle(t)
+
+	b, err := want.ToBoard()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if b.BestWordCount != 2 || b.TotalWords != 2 {
+		t.Errorf("Incorrect word counts, got: %d of %d", b.BestWordCount, b.TotalWords)
+	}
+
+	got, err := puzzle.FromBoard(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	want.Title, want.Author, want.Copyright, want.Notes = "", "", "", ""
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Incorrect board, got: %+v, want: %+v", got, want)
+	}
+}
@@ -0,0 +1,74 @@
+package puzzle
+
+import (
+	"strings"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+)
+
+// clueKey identifies a clue by its number and direction.
+type clueKey struct {
+	number    int
+	direction board.Direction
+}
+
+// gridClues derives the clues of a grid: every run of two or more letters
+// is a word, numbered by board.NewClues. Hints are left empty.
+func gridClues(grid [][]Cell) board.Clues {
+	isLetter := func(x, y int) bool {
+		return y >= 0 && y < len(grid) && x >= 0 && x < len(grid[y]) && !grid[y][x].Block
+	}
+	run := func(x, y int, direction board.Direction) board.PlacedWord {
+		p := board.PlacedWord{Start: board.Location{X: x, Y: y}, Direction: direction}
+		var sb strings.Builder
+		for isLetter(x, y) {
+			sb.WriteString(grid[y][x].Letter)
+			if direction == board.Across {
+				x++
+			} else {
+				y++
+			}
+		}
+		p.Word = sb.String()
+		return p
+	}
+
+	var words []board.PlacedWord
+	for y := range grid {
+		for x := range grid[y] {
+			if !isLetter(x, y) {
+				continue
+			}
+			if !isLetter(x-1, y) && isLetter(x+1, y) {
+				words = append(words, run(x, y, board.Across))
+			}
+			if !isLetter(x, y-1) && isLetter(x, y+1) {
+				words = append(words, run(x, y, board.Down))
+			}
+		}
+	}
+	return board.NewClues(words)
+}
+
+// setEntries sets the across and down entries of the puzzle to the clues
+// and numbers the cells they start in.
+func (p *Puzzle) setEntries(clues board.Clues) {
+	entry := func(c board.Clue) Entry {
+		p.Grid[c.Start.Y][c.Start.X].Number = c.Number
+		return Entry{
+			Number: c.Number,
+			X:      c.Start.X,
+			Y:      c.Start.Y,
+			Length: len([]rune(c.Word)),
+			Answer: strings.ToUpper(c.Word),
+			Hint:   c.Hint,
+		}
+	}
+	p.Across, p.Down = []Entry{}, []Entry{}
+	for _, c := range clues.Across {
+		p.Across = append(p.Across, entry(c))
+	}
+	for _, c := range clues.Down {
+		p.Down = append(p.Down, entry(c))
+	}
+}
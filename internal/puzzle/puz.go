@@ -0,0 +1,370 @@
+package puzzle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+)
+
+// The Across Lite .puz layout. All numbers are little endian and all
+// strings are ISO-8859-1 encoded and NUL terminated.
+const (
+	puzMagic       = "ACROSS&DOWN\x00"
+	puzVersion     = "1.3\x00"
+	puzHeaderSize  = 0x34
+	puzBlock       = '.'
+	puzEmpty       = '-'
+	puzBitmask     = 0x0001 // The "unknown bitmask" every known writer sets.
+	puzUnscrambled = 0x0000
+	puzScrambled   = 0x0004
+)
+
+var (
+	// ErrNotPuz is returned when the data does not look like a .puz file.
+	ErrNotPuz = errors.New("not an Across Lite .puz file")
+	// ErrChecksum is returned when a checksum stored in a .puz file does
+	// not match its contents.
+	ErrChecksum = errors.New("puz checksum mismatch")
+	// ErrScrambled is returned when reading a .puz file whose solution is
+	// locked with a key.
+	ErrScrambled = errors.New("puz solution is scrambled")
+)
+
+// puzFile holds the sections of a .puz file that take part in the
+// checksums.
+type puzFile struct {
+	version       []byte
+	width, height int
+	scrambled     bool
+	solution      []byte
+	state         []byte
+	title         []byte
+	author        []byte
+	copyright     []byte
+	clues         [][]byte
+	notes         []byte
+}
+
+// WritePuz writes the puzzle in the Across Lite .puz format. Clues are
+// written in the order the format expects: by clue number, with the across
+// clue before the down clue when both share a number. The solution is
+// written unscrambled.
+func (p *Puzzle) WritePuz(w io.Writer) error {
+	if p.Width < 1 || p.Width > 255 || p.Height < 1 || p.Height > 255 {
+		return fmt.Errorf("puz grids must be between 1x1 and 255x255, got %dx%d", p.Width, p.Height)
+	}
+
+	f := &puzFile{
+		version:  []byte(puzVersion),
+		width:    p.Width,
+		height:   p.Height,
+		solution: make([]byte, 0, p.Width*p.Height),
+		state:    make([]byte, 0, p.Width*p.Height),
+	}
+	for y, row := range p.Grid {
+		for x, cell := range row {
+			if cell.Block {
+				f.solution = append(f.solution, puzBlock)
+				f.state = append(f.state, puzBlock)
+				continue
+			}
+			letter, err := encodeLatin1(cell.Letter)
+			if err != nil || len(letter) != 1 {
+				return fmt.Errorf("cell (%d, %d) cannot be stored in a puz file: %q", x, y, cell.Letter)
+			}
+			f.solution = append(f.solution, letter[0])
+			f.state = append(f.state, puzEmpty)
+		}
+	}
+
+	clues := gridClues(p.Grid)
+	if len(clues.Across) != len(p.Across) || len(clues.Down) != len(p.Down) {
+		return fmt.Errorf("grid has %d across and %d down entries, puzzle has %d and %d",
+			len(clues.Across), len(clues.Down), len(p.Across), len(p.Down))
+	}
+	hints := make(map[clueKey]string, len(p.Across)+len(p.Down))
+	for _, e := range p.Across {
+		hints[clueKey{e.Number, board.Across}] = e.Hint
+	}
+	for _, e := range p.Down {
+		hints[clueKey{e.Number, board.Down}] = e.Hint
+	}
+	for _, c := range clues.All() {
+		hint, ok := hints[clueKey{c.Number, c.Direction}]
+		if !ok {
+			return fmt.Errorf("no clue for entry %d in the grid", c.Number)
+		}
+		clue, err := encodeLatin1(hint)
+		if err != nil {
+			return fmt.Errorf("clue %d: %w", c.Number, err)
+		}
+		f.clues = append(f.clues, clue)
+	}
+
+	var err error
+	if f.title, err = encodeLatin1(p.Title); err != nil {
+		return fmt.Errorf("title: %w", err)
+	}
+	if f.author, err = encodeLatin1(p.Author); err != nil {
+		return fmt.Errorf("author: %w", err)
+	}
+	if f.copyright, err = encodeLatin1(p.Copyright); err != nil {
+		return fmt.Errorf("copyright: %w", err)
+	}
+	if f.notes, err = encodeLatin1(p.Notes); err != nil {
+		return fmt.Errorf("notes: %w", err)
+	}
+
+	_, err = w.Write(f.bytes())
+	return err
+}
+
+// ReadPuz reads a puzzle in the Across Lite .puz format. All checksums are
+// verified. Clue numbers and answers are derived from the grid.
+func ReadPuz(r io.Reader) (*Puzzle, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < puzHeaderSize || string(data[0x02:0x0E]) != puzMagic {
+		return nil, ErrNotPuz
+	}
+
+	f := &puzFile{
+		version:   data[0x18:0x1C],
+		width:     int(data[0x2C]),
+		height:    int(data[0x2D]),
+		scrambled: binary.LittleEndian.Uint16(data[0x32:])&puzScrambled != 0,
+	}
+	clueCount := int(binary.LittleEndian.Uint16(data[0x2E:]))
+	size := f.width * f.height
+	if len(data) < puzHeaderSize+2*size {
+		return nil, fmt.Errorf("%w: grid is truncated", ErrNotPuz)
+	}
+	f.solution = data[puzHeaderSize : puzHeaderSize+size]
+	f.state = data[puzHeaderSize+size : puzHeaderSize+2*size]
+
+	rest := data[puzHeaderSize+2*size:]
+	next := func() ([]byte, error) {
+		end := bytes.IndexByte(rest, 0)
+		if end < 0 {
+			return nil, fmt.Errorf("%w: unterminated string", ErrNotPuz)
+		}
+		s := rest[:end]
+		rest = rest[end+1:]
+		return s, nil
+	}
+	if f.title, err = next(); err != nil {
+		return nil, err
+	}
+	if f.author, err = next(); err != nil {
+		return nil, err
+	}
+	if f.copyright, err = next(); err != nil {
+		return nil, err
+	}
+	for i := 0; i < clueCount; i++ {
+		clue, err := next()
+		if err != nil {
+			return nil, err
+		}
+		f.clues = append(f.clues, clue)
+	}
+	// Files written before version 1.3 may end without notes.
+	if len(rest) > 0 {
+		if f.notes, err = next(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := f.verify(data); err != nil {
+		return nil, err
+	}
+	if f.scrambled {
+		return nil, ErrScrambled
+	}
+	return f.puzzle()
+}
+
+// puzzle converts the file sections into a puzzle document.
+func (f *puzFile) puzzle() (*Puzzle, error) {
+	p := &Puzzle{
+		Version:   SchemaVersion,
+		Title:     decodeLatin1(f.title),
+		Author:    decodeLatin1(f.author),
+		Copyright: decodeLatin1(f.copyright),
+		Notes:     decodeLatin1(f.notes),
+		Width:     f.width,
+		Height:    f.height,
+		Grid:      make([][]Cell, f.height),
+	}
+	for y := range p.Grid {
+		p.Grid[y] = make([]Cell, f.width)
+		for x := range p.Grid[y] {
+			c := f.solution[y*f.width+x]
+			if c == puzBlock {
+				p.Grid[y][x] = Cell{Block: true}
+				continue
+			}
+			p.Grid[y][x] = Cell{Letter: decodeLatin1([]byte{c})}
+		}
+	}
+
+	clues := gridClues(p.Grid)
+	order := clues.All()
+	if len(order) != len(f.clues) {
+		return nil, fmt.Errorf("grid has %d entries but the file has %d clues", len(order), len(f.clues))
+	}
+	hints := make(map[clueKey]string, len(order))
+	for i, c := range order {
+		hints[clueKey{c.Number, c.Direction}] = decodeLatin1(f.clues[i])
+	}
+	for _, list := range [][]board.Clue{clues.Across, clues.Down} {
+		for i, c := range list {
+			list[i].Hint = hints[clueKey{c.Number, c.Direction}]
+		}
+	}
+	p.setEntries(clues)
+	return p, nil
+}
+
+// bytes returns the complete .puz encoding of the file.
+func (f *puzFile) bytes() []byte {
+	var buf bytes.Buffer
+	header := make([]byte, puzHeaderSize)
+	copy(header[0x02:], puzMagic)
+	copy(header[0x18:], f.version)
+	header[0x2C] = byte(f.width)
+	header[0x2D] = byte(f.height)
+	binary.LittleEndian.PutUint16(header[0x2E:], uint16(len(f.clues)))
+	binary.LittleEndian.PutUint16(header[0x30:], puzBitmask)
+	scrambled := uint16(puzUnscrambled)
+	if f.scrambled {
+		scrambled = puzScrambled
+	}
+	binary.LittleEndian.PutUint16(header[0x32:], scrambled)
+
+	checksum, cib, masked := f.checksums(header)
+	binary.LittleEndian.PutUint16(header[0x00:], checksum)
+	binary.LittleEndian.PutUint16(header[0x0E:], cib)
+	copy(header[0x10:], masked[:])
+
+	buf.Write(header)
+	buf.Write(f.solution)
+	buf.Write(f.state)
+	for _, s := range [][]byte{f.title, f.author, f.copyright} {
+		buf.Write(s)
+		buf.WriteByte(0)
+	}
+	for _, clue := range f.clues {
+		buf.Write(clue)
+		buf.WriteByte(0)
+	}
+	buf.Write(f.notes)
+	buf.WriteByte(0)
+	return buf.Bytes()
+}
+
+// verify compares the checksums stored in the header with the contents.
+func (f *puzFile) verify(data []byte) error {
+	checksum, cib, masked := f.checksums(data[:puzHeaderSize])
+	if binary.LittleEndian.Uint16(data[0x00:]) != checksum {
+		return fmt.Errorf("%w: file checksum", ErrChecksum)
+	}
+	if binary.LittleEndian.Uint16(data[0x0E:]) != cib {
+		return fmt.Errorf("%w: header checksum", ErrChecksum)
+	}
+	if !bytes.Equal(data[0x10:0x18], masked[:]) {
+		return fmt.Errorf("%w: masked checksums", ErrChecksum)
+	}
+	return nil
+}
+
+// checksums computes the file checksum, the header (CIB) checksum and the
+// eight masked checksum bytes for the given header.
+func (f *puzFile) checksums(header []byte) (uint16, uint16, [8]byte) {
+	cib := puzChecksum(header[0x2C:0x34], 0)
+	solution := puzChecksum(f.solution, 0)
+	state := puzChecksum(f.state, 0)
+	text := f.textChecksum(0)
+
+	checksum := puzChecksum(f.solution, cib)
+	checksum = puzChecksum(f.state, checksum)
+	checksum = f.textChecksum(checksum)
+
+	var masked [8]byte
+	low := "ICHE"
+	high := "ATED"
+	for i, sum := range []uint16{cib, solution, state, text} {
+		masked[i] = low[i] ^ byte(sum)
+		masked[i+4] = high[i] ^ byte(sum>>8)
+	}
+	return checksum, cib, masked
+}
+
+// textChecksum adds the strings of the file to the checksum. Clues are
+// added without their terminator, all other non-empty strings with it.
+func (f *puzFile) textChecksum(sum uint16) uint16 {
+	for _, s := range [][]byte{f.title, f.author, f.copyright} {
+		if len(s) > 0 {
+			sum = puzChecksum(append(append([]byte{}, s...), 0), sum)
+		}
+	}
+	for _, clue := range f.clues {
+		sum = puzChecksum(clue, sum)
+	}
+	// Notes only take part in the checksum since version 1.3.
+	if len(f.notes) > 0 && string(f.version) >= "1.3" {
+		sum = puzChecksum(append(append([]byte{}, f.notes...), 0), sum)
+	}
+	return sum
+}
+
+// puzChecksum is the rotating checksum used throughout the .puz format.
+func puzChecksum(data []byte, sum uint16) uint16 {
+	for _, b := range data {
+		if sum&1 == 1 {
+			sum = sum>>1 | 0x8000
+		} else {
+			sum >>= 1
+		}
+		sum += uint16(b)
+	}
+	return sum
+}
+
+// encodeLatin1 converts s to ISO-8859-1, the only encoding .puz files
+// support.
+func encodeLatin1(s string) ([]byte, error) {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r > 0xFF {
+			return nil, fmt.Errorf("character %q is not in ISO-8859-1", r)
+		}
+		out = append(out, byte(r))
+	}
+	return out, nil
+}
+
+// decodeLatin1 converts ISO-8859-1 encoded bytes to a string.
+func decodeLatin1(b []byte) string {
+	var sb strings.Builder
+	for _, c := range b {
+		sb.WriteRune(rune(c))
+	}
+	return sb.String()
+}
+
+// SavePuz writes the .puz encoding of the puzzle to the named file.
+func (p *Puzzle) SavePuz(fileName string, fw board.FileWriter) error {
+	var buf bytes.Buffer
+	if err := p.WritePuz(&buf); err != nil {
+		return err
+	}
+	return fw.WriteFile(fileName, buf.Bytes(), 0644)
+}
@@ -0,0 +1,121 @@
+package puzzle_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/puzzle"
+)
+
+func newTestPuzzle(t *testing.T) *puzzle.Puzzle {
+	t.Helper()
+	p, err := puzzle.FromBoard(newSolvedBoard(t))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	p.Title = "Im Haus"
+	p.Author = "CrizzCrozz"
+	p.Copyright = "© 2024"
+	p.Notes = "Für die Klasse 3b"
+	return p
+}
+
+func TestPuzRoundTrip(t *testing.T) {
+	want := newTestPuzzle(t)
+
+	var buf bytes.Buffer
+	if err := want.WritePuz(&buf); err != nil {
+		t.Fatalf("Unexpected error writing puz: %s", err)
+	}
+	got, err := puzzle.ReadPuz(&buf)
+	if err != nil {
+		t.Fatalf("Unexpected error reading puz: %s", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Incorrect round trip, got: %+v, want: %+v", got, want)
+	}
+}
+
+func TestWritePuz_Layout(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newTestPuzzle(t).WritePuz(&buf); err != nil {
+		t.Fatalf("Unexpected error writing puz: %s", err)
+	}
+	data := buf.Bytes()
+
+	if got := string(data[0x02:0x0E]); got != "ACROSS&DOWN\x00" {
+		t.Errorf("Incorrect magic, got: %q", got)
+	}
+	if data[0x2C] != 5 || data[0x2D] != 3 {
+		t.Errorf("Incorrect size, got: %dx%d, want: 5x3", data[0x2C], data[0x2D])
+	}
+	if data[0x2E] != 2 || data[0x2F] != 0 {
+		t.Errorf("Incorrect clue count, got: %d", data[0x2E])
+	}
+	if data[0x32] != 0 || data[0x33] != 0 {
+		t.Errorf("Expected an unscrambled solution, got flags: %#x", data[0x32])
+	}
+
+	solution := string(data[0x34 : 0x34+15])
+	if solution != "HAUS....E....E." {
+		t.Errorf("Incorrect solution, got: %q", solution)
+	}
+	state := string(data[0x34+15 : 0x34+30])
+	if state != "----....-....-." {
+		t.Errorf("Incorrect player state, got: %q", state)
+	}
+
+	// Clues follow title, author and copyright in clue order, encoded
+	// as ISO-8859-1.
+	strs := bytes.Split(data[0x34+30:], []byte{0})
+	if string(strs[3]) != "Geb\xe4ude" || string(strs[4]) != "Stehendes Gew\xe4sser" {
+		t.Errorf("Incorrect clues, got: %q, %q", strs[3], strs[4])
+	}
+}
+
+func TestReadPuz_Errors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newTestPuzzle(t).WritePuz(&buf); err != nil {
+		t.Fatalf("Unexpected error writing puz: %s", err)
+	}
+	valid := buf.Bytes()
+
+	t.Run("not a puz file", func(t *testing.T) {
+		_, err := puzzle.ReadPuz(bytes.NewReader([]byte("word,hint\nhaus,Gebäude\n")))
+		if !errors.Is(err, puzzle.ErrNotPuz) {
+			t.Errorf("Expected ErrNotPuz, got: %v", err)
+		}
+	})
+
+	t.Run("corrupted solution", func(t *testing.T) {
+		data := append([]byte{}, valid...)
+		data[0x34] = 'X'
+		_, err := puzzle.ReadPuz(bytes.NewReader(data))
+		if !errors.Is(err, puzzle.ErrChecksum) {
+			t.Errorf("Expected ErrChecksum, got: %v", err)
+		}
+	})
+
+	t.Run("corrupted clue", func(t *testing.T) {
+		data := append([]byte{}, valid...)
+		i := bytes.Index(data, []byte("Stehendes"))
+		data[i] = 's'
+		_, err := puzzle.ReadPuz(bytes.NewReader(data))
+		if !errors.Is(err, puzzle.ErrChecksum) {
+			t.Errorf("Expected ErrChecksum, got: %v", err)
+		}
+	})
+}
+
+func TestWritePuz_UnsupportedCharacter(t *testing.T) {
+	p := newTestPuzzle(t)
+	p.Across[0].Hint = "Дом"
+
+	var buf bytes.Buffer
+	if err := p.WritePuz(&buf); err == nil {
+		t.Fatal("Expected an error for a clue outside ISO-8859-1, but got none")
+	}
+}
@@ -0,0 +1,159 @@
+// Package puzzle defines the stable, versioned document that describes a
+// generated crossword for consumers outside of the generator, such as web
+// frontends.
+//
+// A document (schema version 1) looks like this:
+//
+//	{
+//	  "version": 1,
+//	  "title": "Im Haus",
+//	  "seed": 4242,
+//	  "width": 5,
+//	  "height": 3,
+//	  "grid": [
+//	    [{"letter": "H", "number": 1}, {"letter": "A"}, {"letter": "U"}, {"letter": "S", "number": 2}, {"block": true}],
+//	    ...
+//	  ],
+//	  "across": [{"number": 1, "x": 0, "y": 0, "length": 4, "answer": "HAUS", "hint": "Gebäude"}],
+//	  "down":   [{"number": 2, "x": 3, "y": 0, "length": 3, "answer": "SEE", "hint": "Stehendes Gewässer"}]
+//	}
+//
+// Rows in grid run from top to bottom and cells within a row from left to
+// right, so grid[y][x] is the cell at column x and row y. Cells that hold no
+// letter are blocks. Letters are upper case and every cell holds exactly one
+// letter. Entries are sorted by clue number. The title, author, copyright
+// and notes strings are optional and left out when empty. The seed, if
+// present, regenerates the grid from the same words and options.
+package puzzle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+)
+
+// SchemaVersion is the version of the document layout written by this
+// package. It is increased whenever a field changes meaning or is removed.
+const SchemaVersion = 1
+
+// ErrNoSolution is returned when a board has no saved solution to export.
+var ErrNoSolution = errors.New("board has no solution to export")
+
+// Puzzle is the exported form of a generated crossword.
+type Puzzle struct {
+	Version   int      `json:"version"`
+	Title     string   `json:"title,omitempty"`
+	Author    string   `json:"author,omitempty"`
+	Copyright string   `json:"copyright,omitempty"`
+	Notes     string   `json:"notes,omitempty"`
+	Seed      int64    `json:"seed,omitempty"` // The seed the grid was generated with, if known.
+	Width     int      `json:"width"`
+	Height    int      `json:"height"`
+	Grid      [][]Cell `json:"grid"`
+	Across    []Entry  `json:"across"`
+	Down      []Entry  `json:"down"`
+}
+
+// Cell is a single square of the grid.
+type Cell struct {
+	Block  bool   `json:"block,omitempty"`  // The cell holds no letter.
+	Letter string `json:"letter,omitempty"` // The solution letter of the cell.
+	Number int    `json:"number,omitempty"` // The clue number printed in the cell, if any.
+}
+
+// Entry is a single answer together with its clue.
+type Entry struct {
+	Number int    `json:"number"` // The clue number shared with the grid.
+	X      int    `json:"x"`      // Column of the first letter.
+	Y      int    `json:"y"`      // Row of the first letter.
+	Length int    `json:"length"` // Number of letters (not bytes) in the answer.
+	Answer string `json:"answer"`
+	Hint   string `json:"hint"`
+}
+
+// FromBoard builds a puzzle document from the best solution saved on the
+// board. The entries take their hints from the placed words.
+func FromBoard(b *board.Board) (*Puzzle, error) {
+	if b == nil || b.BestBoard == nil {
+		return nil, ErrNoSolution
+	}
+
+	height := len(b.BestBoard)
+	width := 0
+	if height > 0 {
+		width = len(b.BestBoard[0])
+	}
+
+	p := &Puzzle{
+		Version: SchemaVersion,
+		Seed:    b.Seed,
+		Width:   width,
+		Height:  height,
+		Grid:    make([][]Cell, height),
+	}
+	for y, row := range b.BestBoard {
+		p.Grid[y] = make([]Cell, width)
+		for x, cell := range row {
+			if cell == nil || !cell.Filled {
+				p.Grid[y][x] = Cell{Block: true}
+				continue
+			}
+			p.Grid[y][x] = Cell{Letter: strings.ToUpper(cell.Character)}
+		}
+	}
+
+	p.setEntries(b.Clues())
+
+	return p, nil
+}
+
+// ToBoard places the entries of the puzzle on a new board and saves them as
+// its best solution, so puzzles read from a file can be edited or numbered
+// again. The placed words keep the hints of the entries.
+func (p *Puzzle) ToBoard() (*board.Board, error) {
+	bounds, err := board.NewBoundsRectangle(p.Width, p.Height)
+	if err != nil {
+		return nil, err
+	}
+	b := board.NewBoard(bounds, len(p.Across)+len(p.Down), &board.OSFileWriter{})
+	b.Seed = p.Seed
+
+	place := func(entries []Entry, direction board.Direction) error {
+		for _, e := range entries {
+			if !b.Fits(board.Location{X: e.X, Y: e.Y}, e.Answer, direction) {
+				return fmt.Errorf("entry %d (%s) does not fit on a %dx%d board", e.Number, e.Answer, p.Width, p.Height)
+			}
+			entry := words.Entry{Word: e.Answer, Hint: e.Hint}
+			if err := b.PlaceEntryAt(board.Location{X: e.X, Y: e.Y}, entry, direction); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := place(p.Across, board.Across); err != nil {
+		return nil, err
+	}
+	if err := place(p.Down, board.Down); err != nil {
+		return nil, err
+	}
+	b.SaveBestSolution()
+	return b, nil
+}
+
+// Marshal returns the indented JSON encoding of the puzzle.
+func (p *Puzzle) Marshal() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// Save writes the JSON encoding of the puzzle to the named file.
+func (p *Puzzle) Save(fileName string, fw board.FileWriter) error {
+	data, err := p.Marshal()
+	if err != nil {
+		return err
+	}
+	return fw.WriteFile(fileName, data, 0644)
+}
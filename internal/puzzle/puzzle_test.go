@@ -0,0 +1,110 @@
+package puzzle_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/puzzle"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+)
+
+// newSolvedBoard returns a 5x3 board with "haus" across and "see" down
+// sharing the letter s, and saves it as the best solution.
+func newSolvedBoard(t *testing.T) *board.Board {
+	t.Helper()
+	bounds, err := board.NewBoundsRectangle(5, 3)
+	if err != nil {
+		t.Fatalf("Failed to create bounds: %s", err)
+	}
+	b := board.NewBoard(bounds, 2, &board.OSFileWriter{})
+	b.PlaceEntryAt(board.Location{X: 0, Y: 0}, words.Entry{Word: "haus", Hint: "Gebäude"}, board.Across)
+	b.PlaceEntryAt(board.Location{X: 3, Y: 0}, words.Entry{Word: "see", Hint: "Stehendes Gewässer"}, board.Down)
+	b.SaveBestSolution()
+	return b
+}
+
+type mockFileWriter struct {
+	name string
+	data []byte
+}
+
+func (m *mockFileWriter) WriteFile(name string, data []byte, _ os.FileMode) error {
+	m.name = name
+	m.data = data
+	return nil
+}
+
+func TestFromBoard(t *testing.T) {
+	p, err := puzzle.FromBoard(newSolvedBoard(t))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if p.Version != puzzle.SchemaVersion || p.Width != 5 || p.Height != 3 {
+		t.Fatalf("Incorrect header, got: version %d %dx%d", p.Version, p.Width, p.Height)
+	}
+
+	t.Run("grid", func(t *testing.T) {
+		want := [][]puzzle.Cell{
+			{{Letter: "H", Number: 1}, {Letter: "A"}, {Letter: "U"}, {Letter: "S", Number: 2}, {Block: true}},
+			{{Block: true}, {Block: true}, {Block: true}, {Letter: "E"}, {Block: true}},
+			{{Block: true}, {Block: true}, {Block: true}, {Letter: "E"}, {Block: true}},
+		}
+		for y := range want {
+			for x := range want[y] {
+				if p.Grid[y][x] != want[y][x] {
+					t.Errorf("Incorrect cell at (%d, %d), got: %+v, want: %+v", x, y, p.Grid[y][x], want[y][x])
+				}
+			}
+		}
+	})
+
+	t.Run("entries", func(t *testing.T) {
+		wantAcross := puzzle.Entry{Number: 1, X: 0, Y: 0, Length: 4, Answer: "HAUS", Hint: "Gebäude"}
+		wantDown := puzzle.Entry{Number: 2, X: 3, Y: 0, Length: 3, Answer: "SEE", Hint: "Stehendes Gewässer"}
+		if len(p.Across) != 1 || p.Across[0] != wantAcross {
+			t.Errorf("Incorrect across entries, got: %+v, want: %+v", p.Across, wantAcross)
+		}
+		if len(p.Down) != 1 || p.Down[0] != wantDown {
+			t.Errorf("Incorrect down entries, got: %+v, want: %+v", p.Down, wantDown)
+		}
+	})
+}
+
+func TestFromBoard_NoSolution(t *testing.T) {
+	bounds, _ := board.NewBoundsRectangle(3, 3)
+	b := board.NewBoard(bounds, 1, &board.OSFileWriter{})
+
+	_, err := puzzle.FromBoard(b)
+	if !errors.Is(err, puzzle.ErrNoSolution) {
+		t.Fatalf("Expected ErrNoSolution, got: %v", err)
+	}
+}
+
+func TestSave(t *testing.T) {
+	p, err := puzzle.FromBoard(newSolvedBoard(t))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	fw := &mockFileWriter{}
+	if err := p.Save("out.json", fw); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if fw.name != "out.json" {
+		t.Errorf("Incorrect file name, got: %s, want: out.json", fw.name)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(fw.data, &decoded); err != nil {
+		t.Fatalf("Saved data is not valid JSON: %s", err)
+	}
+	for _, key := range []string{"version", "width", "height", "grid", "across", "down"} {
+		if _, ok := decoded[key]; !ok {
+			t.Errorf("Missing key %q in saved document", key)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package render
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+
+	"github.com/Germanicus1/crizzcrozz/internal/puzzle"
+)
+
+//go:embed player.html
+var playerHTML string
+
+var playerTemplate = template.Must(template.New("player").Parse(playerHTML))
+
+// HTML writes a single self-contained HTML page for solving the puzzle in
+// a browser. The page embeds the puzzle with its styles and scripts and
+// loads nothing from the network. Players select entries by clicking cells
+// or clues, type letters, toggle the direction with the space bar, check or
+// reveal a letter, a word or the grid, and see a timer.
+func HTML(w io.Writer, p *puzzle.Puzzle) error {
+	return playerTemplate.Execute(w, p)
+}
@@ -0,0 +1,35 @@
+package render_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/render"
+)
+
+func TestHTML(t *testing.T) {
+	p := newTestPuzzle()
+	p.Across[0].Hint = `</script><script>alert("x")</script>`
+
+	var buf bytes.Buffer
+	if err := render.HTML(&buf, p); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	page := buf.String()
+
+	if !strings.Contains(page, "<title>Haus &amp; See</title>") {
+		t.Errorf("Expected the escaped title in the page")
+	}
+	if !strings.Contains(page, `"answer":"HAUS"`) || !strings.Contains(page, `"hint":"Stehendes Gewässer"`) {
+		t.Errorf("Expected the puzzle to be embedded as JSON")
+	}
+	if strings.Count(page, "</script>") != 1 {
+		t.Errorf("Expected hints to be escaped inside the script")
+	}
+	for _, external := range []string{"http://", "https://", "src="} {
+		if strings.Contains(page, external) {
+			t.Errorf("Expected no external resources, found %q", external)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Germanicus1/crizzcrozz/internal/puzzle"
+)
+
+// Paper is a page size in PDF points (1/72 inch).
+type Paper struct {
+	Name          string
+	Width, Height float64
+}
+
+var (
+	// A4 is the ISO 216 A4 paper size.
+	A4 = Paper{Name: "A4", Width: 595.28, Height: 841.89}
+	// Letter is the US Letter paper size.
+	Letter = Paper{Name: "Letter", Width: 612, Height: 792}
+)
+
+// PaperByName returns the paper size with the given name, ignoring case.
+func PaperByName(name string) (Paper, error) {
+	for _, paper := range []Paper{A4, Letter} {
+		if strings.EqualFold(paper.Name, name) {
+			return paper, nil
+		}
+	}
+	return Paper{}, fmt.Errorf("unknown paper size %q, use A4 or Letter", name)
+}
+
+// PDFOptions controls the layout of a PDF worksheet.
+type PDFOptions struct {
+	Paper   Paper // The page size; A4 if zero.
+	Answers bool  // Add a page with the solved grid.
+}
+
+// Layout of the worksheet in points.
+const (
+	pdfMargin       = 50.0
+	pdfGutter       = 20.0
+	pdfMaxCellSize  = 28.0
+	pdfGridShare    = 0.55 // The share of the page height the grid may use.
+	pdfTitleSize    = 16.0
+	pdfHeadingSize  = 12.0
+	pdfClueSize     = 10.0
+	pdfClueLeading  = 13.0
+	pdfNumberIndent = 22.0
+)
+
+// PDF writes a printable worksheet: the empty grid followed by numbered
+// Across and Down clue lists, which continue on further pages when they do
+// not fit. With opts.Answers the solved grid is added on a page of its own.
+func PDF(w io.Writer, p *puzzle.Puzzle, opts PDFOptions) error {
+	paper := opts.Paper
+	if paper.Width == 0 || paper.Height == 0 {
+		paper = A4
+	}
+	if p.Width < 1 || p.Height < 1 {
+		return fmt.Errorf("cannot print an empty %dx%d grid", p.Width, p.Height)
+	}
+
+	doc := &pdfDocument{paper: paper}
+	page := doc.newPage()
+	top := paper.Height - pdfMargin
+	if p.Title != "" {
+		page.text(pdfBold, pdfTitleSize, pdfMargin, top-pdfTitleSize, p.Title)
+		top -= pdfTitleSize * 2
+	}
+	gridBottom := drawGrid(page, p, top, false)
+
+	doc.flowClues(page, p, gridBottom-pdfHeadingSize*2)
+
+	if opts.Answers {
+		page = doc.newPage()
+		top = paper.Height - pdfMargin
+		title := "Solution"
+		if p.Title != "" {
+			title = p.Title + " - " + title
+		}
+		page.text(pdfBold, pdfTitleSize, pdfMargin, top-pdfTitleSize, title)
+		drawGrid(page, p, top-pdfTitleSize*2, true)
+	}
+
+	return doc.write(w)
+}
+
+// drawGrid draws the grid centred below top and returns the y coordinate of
+// its bottom edge.
+func drawGrid(page *pdfPage, p *puzzle.Puzzle, top float64, solution bool) float64 {
+	paper := page.paper
+	size := (paper.Width - 2*pdfMargin) / float64(p.Width)
+	if maxSize := (paper.Height - 2*pdfMargin) * pdfGridShare / float64(p.Height); maxSize < size {
+		size = maxSize
+	}
+	if size > pdfMaxCellSize {
+		size = pdfMaxCellSize
+	}
+	left := (paper.Width - size*float64(p.Width)) / 2
+
+	for y, row := range p.Grid {
+		for x, cell := range row {
+			cellLeft := left + float64(x)*size
+			cellTop := top - float64(y)*size
+			page.rect(cellLeft, cellTop-size, size, size, cell.Block)
+			if cell.Number > 0 {
+				numberSize := size * 0.3
+				page.text(pdfRegular, numberSize, cellLeft+1.5, cellTop-numberSize, strconv.Itoa(cell.Number))
+			}
+			if solution && !cell.Block {
+				letterSize := size * 0.6
+				width := textWidth(cell.Letter, letterSize)
+				page.text(pdfRegular, letterSize, cellLeft+(size-width)/2, cellTop-size*0.8, cell.Letter)
+			}
+		}
+	}
+	return top - float64(p.Height)*size
+}
+
+// flowClues writes the Across and Down clue lists in two columns, starting
+// on page below top and adding pages as needed.
+func (d *pdfDocument) flowClues(page *pdfPage, p *puzzle.Puzzle, top float64) {
+	columnWidth := (d.paper.Width - 2*pdfMargin - pdfGutter) / 2
+	column := 0
+	columnTop := top
+	y := columnTop
+
+	// makeRoom moves on to the next column, or to a new page, unless
+	// height more points fit into the current column.
+	makeRoom := func(height float64) {
+		if y-height >= pdfMargin {
+			return
+		}
+		column++
+		if column == 2 {
+			page = d.newPage()
+			column = 0
+			columnTop = d.paper.Height - pdfMargin
+		}
+		y = columnTop
+	}
+
+	for _, section := range []struct {
+		heading string
+		entries []puzzle.Entry
+	}{
+		{"Across", p.Across},
+		{"Down", p.Down},
+	} {
+		if len(section.entries) == 0 {
+			continue
+		}
+		// Keep the heading together with the first line of its first clue.
+		makeRoom(pdfHeadingSize*1.5 + pdfClueLeading)
+		left := pdfMargin + float64(column)*(columnWidth+pdfGutter)
+		page.text(pdfBold, pdfHeadingSize, left, y-pdfHeadingSize, section.heading)
+		y -= pdfHeadingSize * 1.5
+
+		for _, e := range section.entries {
+			hint := fmt.Sprintf("%s (%d)", e.Hint, e.Length)
+			lines := wrapText(hint, pdfClueSize, columnWidth-pdfNumberIndent)
+			makeRoom(float64(len(lines)) * pdfClueLeading)
+			left = pdfMargin + float64(column)*(columnWidth+pdfGutter)
+			number := strconv.Itoa(e.Number) + "."
+			page.text(pdfBold, pdfClueSize, left+pdfNumberIndent-4-textWidth(number, pdfClueSize), y-pdfClueSize, number)
+			for _, line := range lines {
+				page.text(pdfRegular, pdfClueSize, left+pdfNumberIndent, y-pdfClueSize, line)
+				y -= pdfClueLeading
+			}
+		}
+		y -= pdfClueLeading / 2
+	}
+}
+
+// wrapText breaks s into lines no wider than width at the given font size.
+// Words wider than a line are broken between letters.
+func wrapText(s string, size, width float64) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if textWidth(candidate, size) <= width {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		line = ""
+		for _, r := range word {
+			if line != "" && textWidth(line+string(r), size) > width {
+				lines = append(lines, line)
+				line = ""
+			}
+			line += string(r)
+		}
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// textWidth returns the width of s in Helvetica at the given size.
+func textWidth(s string, size float64) float64 {
+	units := 0
+	for _, r := range s {
+		units += helveticaWidth(r)
+	}
+	return float64(units) * size / 1000
+}
+
+// helveticaWidths holds the advance widths of the printable ASCII
+// characters in Helvetica, in 1/1000 of the font size.
+var helveticaWidths = [...]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556, // 0 to ?
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, // @ to O
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556, // P to _
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, // ` to o
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, // p to ~
+}
+
+// helveticaWidth returns the advance width of r. Letters with diacritics
+// are as wide as their base letter; other characters get an average width.
+func helveticaWidth(r rune) int {
+	switch {
+	case r >= ' ' && r <= '~':
+		return helveticaWidths[r-' ']
+	case strings.ContainsRune("ÀÁÂÃÄÅ", r):
+		return 667
+	case strings.ContainsRune("ÒÓÔÕÖ", r):
+		return 778
+	case strings.ContainsRune("ÙÚÛÜ", r):
+		return 722
+	case strings.ContainsRune("ìíîï", r):
+		return 278
+	case r == 'ß':
+		return 611
+	}
+	return 556
+}
+
+// The two fonts used on a worksheet.
+const (
+	pdfRegular = "F1"
+	pdfBold    = "F2"
+)
+
+// pdfDocument collects the pages of a PDF file.
+type pdfDocument struct {
+	paper Paper
+	pages []*pdfPage
+}
+
+// pdfPage is the content stream of a single page.
+type pdfPage struct {
+	paper   Paper
+	content bytes.Buffer
+}
+
+func (d *pdfDocument) newPage() *pdfPage {
+	page := &pdfPage{paper: d.paper}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// rect draws a rectangle with its lower left corner at x, y. Shaded
+// rectangles are filled grey.
+func (pg *pdfPage) rect(x, y, width, height float64, shaded bool) {
+	op := "S"
+	if shaded {
+		op = "B"
+	}
+	fmt.Fprintf(&pg.content, "0.8 g %.2f %.2f %.2f %.2f re %s\n", x, y, width, height, op)
+}
+
+// text draws s with its baseline starting at x, y.
+func (pg *pdfPage) text(font string, size, x, y float64, s string) {
+	fmt.Fprintf(&pg.content, "0 g BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfString(s))
+}
+
+// pdfString encodes s for a literal string in WinAnsiEncoding. Characters
+// the encoding cannot represent are replaced by a question mark.
+func pdfString(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		c, ok := winAnsi(r)
+		if !ok {
+			c = '?'
+		}
+		if c == '(' || c == ')' || c == '\\' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// winAnsi returns the WinAnsiEncoding (Windows-1252) byte for r.
+func winAnsi(r rune) (byte, bool) {
+	switch {
+	case r < 0x80 || (r >= 0xA0 && r <= 0xFF):
+		return byte(r), true
+	case r == '€':
+		return 0x80, true
+	case r == '‚':
+		return 0x82, true
+	case r == '„':
+		return 0x84, true
+	case r == '…':
+		return 0x85, true
+	case r == '‘':
+		return 0x91, true
+	case r == '’':
+		return 0x92, true
+	case r == '“':
+		return 0x93, true
+	case r == '”':
+		return 0x94, true
+	case r == '–':
+		return 0x96, true
+	case r == '—':
+		return 0x97, true
+	}
+	return 0, false
+}
+
+// write writes the document with its cross-reference table.
+func (d *pdfDocument) write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	offset := 0
+	var offsets []int
+	put := func(format string, args ...any) {
+		n, _ := fmt.Fprintf(bw, format, args...)
+		offset += n
+	}
+	object := func(body string) {
+		offsets = append(offsets, offset)
+		put("%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	put("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1 to 4 are fixed; each page adds a page and a content object.
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /%s 3 0 R /%s 4 0 R >> >> /Contents %d 0 R >>",
+			d.paper.Width, d.paper.Height, pdfRegular, pdfBold, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.content.Len(), page.content.String()))
+	}
+
+	xref := offset
+	put("xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, o := range offsets {
+		put("%010d 00000 n \n", o)
+	}
+	put("trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return bw.Flush()
+}
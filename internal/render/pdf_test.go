@@ -0,0 +1,107 @@
+package render_test
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/puzzle"
+	"github.com/Germanicus1/crizzcrozz/internal/render"
+)
+
+// checkPDF verifies the cross-reference table of a PDF file and returns
+// its number of pages.
+func checkPDF(t *testing.T, data []byte) int {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatalf("Missing PDF header or trailer")
+	}
+
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	if m == nil {
+		t.Fatalf("Missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(data[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref does not point to the xref table")
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xref:], -1)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		want := fmt.Sprintf("%d 0 obj", i+1)
+		if !bytes.HasPrefix(data[offset:], []byte(want)) {
+			t.Errorf("xref entry %d does not point to %q", i+1, want)
+		}
+	}
+
+	return bytes.Count(data, []byte("/Type /Page "))
+}
+
+func TestPDF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := render.PDF(&buf, newTestPuzzle(), render.PDFOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	data := buf.Bytes()
+
+	if pages := checkPDF(t, data); pages != 1 {
+		t.Errorf("Incorrect number of pages, got: %d, want: 1", pages)
+	}
+	if !bytes.Contains(data, []byte("/MediaBox [0 0 595.28 841.89]")) {
+		t.Errorf("Expected an A4 page by default")
+	}
+	for _, want := range []string{"(Across)", "(Down)", "(Geb\xe4ude \\(4\\))", "(Stehendes Gew\xe4sser \\(3\\))", "(Haus & See)"} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("Expected %q in the output", want)
+		}
+	}
+	if bytes.Contains(data, []byte("(HAUS)")) || bytes.Contains(data, []byte("(H)")) {
+		t.Errorf("Expected no solution letters without the answer key")
+	}
+}
+
+func TestPDF_AnswersOnLetter(t *testing.T) {
+	var buf bytes.Buffer
+	if err := render.PDF(&buf, newTestPuzzle(), render.PDFOptions{Paper: render.Letter, Answers: true}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	data := buf.Bytes()
+
+	if pages := checkPDF(t, data); pages != 2 {
+		t.Errorf("Incorrect number of pages, got: %d, want: 2", pages)
+	}
+	if !bytes.Contains(data, []byte("/MediaBox [0 0 612.00 792.00]")) {
+		t.Errorf("Expected Letter pages")
+	}
+	if !bytes.Contains(data, []byte("(Haus & See - Solution)")) || !bytes.Contains(data, []byte("(H)")) {
+		t.Errorf("Expected the answer key page")
+	}
+}
+
+func TestPDF_LongClueLists(t *testing.T) {
+	p := newTestPuzzle()
+	long := strings.Repeat("Ein sehr langer Hinweis, der umbrochen werden muss. ", 3)
+	for i := 0; i < 60; i++ {
+		p.Across = append(p.Across, puzzle.Entry{Number: i + 3, Length: 5, Hint: long})
+	}
+
+	var buf bytes.Buffer
+	if err := render.PDF(&buf, p, render.PDFOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if pages := checkPDF(t, buf.Bytes()); pages < 2 {
+		t.Errorf("Expected the clues to continue on a second page, got: %d pages", pages)
+	}
+}
+
+func TestPaperByName(t *testing.T) {
+	if paper, err := render.PaperByName("letter"); err != nil || paper != render.Letter {
+		t.Errorf("Incorrect paper, got: %+v, %v", paper, err)
+	}
+	if _, err := render.PaperByName("A5"); err == nil {
+		t.Errorf("Expected an error for an unknown paper size")
+	}
+}
@@ -0,0 +1,68 @@
+// Package render draws puzzles for printing and for the screen.
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+
+	"github.com/Germanicus1/crizzcrozz/internal/puzzle"
+)
+
+// DefaultCellSize is the edge length of a grid cell in SVG user units.
+const DefaultCellSize = 32
+
+// SVGOptions controls how a grid is drawn.
+type SVGOptions struct {
+	CellSize int  // Edge length of a cell; DefaultCellSize if zero.
+	Solution bool // Draw the solution letters into the grid.
+}
+
+// SVG draws the grid of the puzzle as a standalone SVG document. Cells are
+// outlined with the clue number in the top left corner, blocks are shaded,
+// and the solution letters are drawn when opts.Solution is set.
+func SVG(w io.Writer, p *puzzle.Puzzle, opts SVGOptions) error {
+	size := opts.CellSize
+	if size <= 0 {
+		size = DefaultCellSize
+	}
+	// Leave a margin of one stroke width so the outer lines are not cut.
+	width := p.Width*size + 2
+	height := p.Height*size + 2
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(bw, "<style>\n"+
+		".cell { fill: #fff; stroke: #000; stroke-width: 1; }\n"+
+		".block { fill: #c8c8c8; stroke: #000; stroke-width: 1; }\n"+
+		".number { font: %dpx sans-serif; fill: #000; }\n"+
+		".letter { font: %dpx sans-serif; fill: #000; text-anchor: middle; }\n"+
+		"</style>\n", size*3/10, size*6/10)
+	if p.Title != "" {
+		fmt.Fprintf(bw, "<title>%s</title>\n", html.EscapeString(p.Title))
+	}
+
+	for y, row := range p.Grid {
+		for x, cell := range row {
+			left := 1 + x*size
+			top := 1 + y*size
+			class := "cell"
+			if cell.Block {
+				class = "block"
+			}
+			fmt.Fprintf(bw, `<rect class="%s" x="%d" y="%d" width="%d" height="%d"/>`+"\n", class, left, top, size, size)
+			if cell.Number > 0 {
+				fmt.Fprintf(bw, `<text class="number" x="%d" y="%d">%d</text>`+"\n", left+2, top+size*3/10, cell.Number)
+			}
+			if opts.Solution && !cell.Block {
+				fmt.Fprintf(bw, `<text class="letter" x="%d" y="%d">%s</text>`+"\n",
+					left+size/2, top+size*8/10, html.EscapeString(cell.Letter))
+			}
+		}
+	}
+
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
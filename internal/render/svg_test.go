@@ -0,0 +1,104 @@
+package render_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/puzzle"
+	"github.com/Germanicus1/crizzcrozz/internal/render"
+)
+
+// newTestPuzzle returns a 5x3 puzzle with "HAUS" across and "SEE" down.
+func newTestPuzzle() *puzzle.Puzzle {
+	block := puzzle.Cell{Block: true}
+	return &puzzle.Puzzle{
+		Version: puzzle.SchemaVersion,
+		Title:   "Haus & See",
+		Width:   5,
+		Height:  3,
+		Grid: [][]puzzle.Cell{
+			{{Letter: "H", Number: 1}, {Letter: "A"}, {Letter: "U"}, {Letter: "S", Number: 2}, block},
+			{block, block, block, {Letter: "E"}, block},
+			{block, block, block, {Letter: "E"}, block},
+		},
+		Across: []puzzle.Entry{{Number: 1, X: 0, Y: 0, Length: 4, Answer: "HAUS", Hint: "Gebäude"}},
+		Down:   []puzzle.Entry{{Number: 2, X: 3, Y: 0, Length: 3, Answer: "SEE", Hint: "Stehendes Gewässer"}},
+	}
+}
+
+// svgElements parses the document and returns the elements by class.
+func svgElements(t *testing.T, data []byte) map[string][]string {
+	t.Helper()
+	elements := make(map[string][]string)
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	class := ""
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return elements
+		}
+		if err != nil {
+			t.Fatalf("Output is not valid XML: %s", err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			class = ""
+			for _, attr := range tok.Attr {
+				if attr.Name.Local == "class" {
+					class = attr.Value
+				}
+			}
+			if tok.Name.Local == "rect" {
+				elements[class] = append(elements[class], "")
+			}
+		case xml.EndElement:
+			class = ""
+		case xml.CharData:
+			if class == "number" || class == "letter" {
+				elements[class] = append(elements[class], string(tok))
+			}
+		}
+	}
+}
+
+func TestSVG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := render.SVG(&buf, newTestPuzzle(), render.SVGOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	elements := svgElements(t, buf.Bytes())
+	if got := len(elements["cell"]); got != 6 {
+		t.Errorf("Incorrect number of cells, got: %d, want: 6", got)
+	}
+	if got := len(elements["block"]); got != 9 {
+		t.Errorf("Incorrect number of blocks, got: %d, want: 9", got)
+	}
+	if got := strings.Join(elements["number"], ","); got != "1,2" {
+		t.Errorf("Incorrect clue numbers, got: %s, want: 1,2", got)
+	}
+	if got := len(elements["letter"]); got != 0 {
+		t.Errorf("Expected no letters without the solution, got: %d", got)
+	}
+	if !strings.Contains(buf.String(), "<title>Haus &amp; See</title>") {
+		t.Errorf("Expected the escaped title in the output")
+	}
+}
+
+func TestSVG_Solution(t *testing.T) {
+	var buf bytes.Buffer
+	if err := render.SVG(&buf, newTestPuzzle(), render.SVGOptions{CellSize: 20, Solution: true}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	elements := svgElements(t, buf.Bytes())
+	if got := strings.Join(elements["letter"], ""); got != "HAUSEE" {
+		t.Errorf("Incorrect solution letters, got: %s, want: HAUSEE", got)
+	}
+	if !strings.Contains(buf.String(), `width="102" height="62"`) {
+		t.Errorf("Expected a 102x62 document for 20 unit cells")
+	}
+}
@@ -0,0 +1,277 @@
+// Package server exposes crossword generation as a local HTTP JSON API.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/crossword"
+	"github.com/Germanicus1/crizzcrozz/internal/generators"
+	"github.com/Germanicus1/crizzcrozz/internal/puzzle"
+	"github.com/Germanicus1/crizzcrozz/pkg/models"
+)
+
+// The limits of the requests accepted.
+const (
+	MaxRequestSize = 1 << 20 // The largest request body, in bytes.
+	MaxSize        = 100     // The largest side of a board, and so the longest word.
+	MaxWords       = 500     // The largest number of words, as the estimated board grows with it.
+	MaxRetries     = 100     // The largest number of attempts.
+)
+
+// DefaultMaxNodes is the number of word placements each attempt of a
+// generation may try. Not all generators backtrack, so the nodes limit the
+// work of those that don't.
+const DefaultMaxNodes = 1000000
+
+// BuildFunc builds a crossword board from words and hints.
+type BuildFunc func(ctx context.Context, wordsAndHints []*models.WordsAndHints, opts crossword.Options) (*crossword.Result, error)
+
+// Server handles the API requests. The zero value is not usable, create
+// servers with New.
+type Server struct {
+	Timeout time.Duration     // The max time a request may take.
+	Budget  generators.Budget // Limits the work of each attempt of a generation.
+	Build   BuildFunc         // Builds the boards; crossword.Build by default.
+
+	slots chan struct{} // Limits the number of concurrent generations.
+}
+
+// Word is a word with its hint as posted by clients.
+type Word struct {
+	Word string `json:"word"`
+	Hint string `json:"hint"`
+}
+
+// Pin is a word placed by hand as posted by clients. X and Y are the column
+// and row of its first letter, counted from 0.
+type Pin struct {
+	Word      string `json:"word"`
+	Hint      string `json:"hint"`
+	X         int    `json:"x"`
+	Y         int    `json:"y"`
+	Direction string `json:"direction"` // Either across or down.
+}
+
+// Request is the body of a POST /puzzles request.
+type Request struct {
+	Words     []Word `json:"words"`
+	Pinned    []Pin  `json:"pinned,omitempty"`    // Words placed before the others, added to the words if missing.
+	Size      int    `json:"size,omitempty"`      // The width of the board; estimated if zero.
+	Height    int    `json:"height,omitempty"`    // The height of the board; the size if zero.
+	Retries   int    `json:"retries,omitempty"`   // The max number of attempts; one if zero.
+	Generator string `json:"generator,omitempty"` // The name of the generator; the default if empty.
+	Seed      int64  `json:"seed,omitempty"`      // The seed of the random choices; random if zero.
+	// Objective is what makes a board good, a preset like "compact" or
+	// weights like "words=1,interlock=0.2"; the default if empty.
+	Objective string `json:"objective,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Author    string `json:"author,omitempty"`
+}
+
+// Response is the body of a successful POST /puzzles response. If not all
+// words could be placed, the puzzle holds the best partial board and the
+// reason tells why the generator stopped.
+type Response struct {
+	Puzzle *puzzle.Puzzle `json:"puzzle"`
+	Placed int            `json:"placed"` // The number of words on the board.
+	Total  int            `json:"total"`  // The number of words requested, pinned ones included.
+	Reason string         `json:"reason"` // Why the generator stopped, "complete" if all words were placed.
+	// Score is the score of the board by the objective of the request, and
+	// Breakdown the terms it adds up.
+	Score     float64              `json:"score"`
+	Breakdown generators.Breakdown `json:"breakdown,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// New returns a server that stops generations after timeout and runs at
+// most maxConcurrent generations at a time. Each attempt of a generation
+// may backtrack generators.DefaultMaxBacktracks times and try
+// DefaultMaxNodes placements.
+func New(timeout time.Duration, maxConcurrent int) *Server {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	return &Server{
+		Timeout: timeout,
+		Budget:  generators.Budget{MaxBacktracks: generators.DefaultMaxBacktracks, MaxNodes: DefaultMaxNodes},
+		Build:   crossword.Build,
+		slots:   make(chan struct{}, maxConcurrent),
+	}
+}
+
+// Handler returns the HTTP handler of the API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /puzzles", s.handlePuzzles)
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	return mux
+}
+
+func (s *Server) handlePuzzles(w http.ResponseWriter, r *http.Request) {
+	var req Request
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxRequestSize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %w", err))
+		return
+	}
+	wordsAndHints, err := req.validate()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	objective, err := req.objective()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := r.Context()
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
+	// Wait for a free slot, but not longer than the request may take.
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		writeError(w, http.StatusServiceUnavailable, errors.New("too many concurrent generations"))
+		return
+	}
+	defer func() { <-s.slots }()
+
+	// A generation takes one slot, so it runs a single search.
+	res, err := s.Build(ctx, wordsAndHints, crossword.Options{
+		Width:      req.Size,
+		Height:     req.Height,
+		MaxRetries: req.Retries,
+		Generator:  req.Generator,
+		Seed:       req.Seed,
+		Pinned:     req.pinnedWords(),
+		Objective:  objective,
+		Budget:     s.Budget,
+		Workers:    1,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, generators.ErrUnknownGenerator), errors.Is(err, generators.ErrInvalidPin), errors.Is(err, generators.ErrInvalidSymmetry):
+			writeError(w, http.StatusBadRequest, err)
+		case errors.Is(err, crossword.ErrNoWordsPlaced):
+			writeError(w, http.StatusUnprocessableEntity, err)
+		case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+			writeError(w, http.StatusServiceUnavailable, errors.New("puzzle generation timed out"))
+		default:
+			writeError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	p, err := puzzle.FromBoard(res.Board)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	p.Title = req.Title
+	p.Author = req.Author
+
+	writeJSON(w, http.StatusOK, Response{
+		Puzzle:    p,
+		Placed:    res.Board.BestWordCount,
+		Total:     res.Board.TotalWords,
+		Reason:    res.Reason.String(),
+		Score:     res.Score.Score(),
+		Breakdown: res.Score,
+	})
+}
+
+// validate checks the request and returns its words and hints.
+func (req *Request) validate() ([]*models.WordsAndHints, error) {
+	if len(req.Words) == 0 && len(req.Pinned) == 0 {
+		return nil, errors.New("no words given")
+	}
+	if n := len(req.Words) + len(req.Pinned); n > MaxWords {
+		return nil, fmt.Errorf("too many words %d, want at most %d", n, MaxWords)
+	}
+	if req.Size < 0 || req.Size > MaxSize {
+		return nil, fmt.Errorf("invalid size %d, want at most %d", req.Size, MaxSize)
+	}
+	if req.Height < 0 || req.Height > MaxSize {
+		return nil, fmt.Errorf("invalid height %d, want at most %d", req.Height, MaxSize)
+	}
+	if req.Retries < 0 || req.Retries > MaxRetries {
+		return nil, fmt.Errorf("invalid retries %d, want at most %d", req.Retries, MaxRetries)
+	}
+	wordsAndHints := make([]*models.WordsAndHints, 0, len(req.Words))
+	for i, w := range req.Words {
+		word := strings.TrimSpace(w.Word)
+		if word == "" {
+			return nil, fmt.Errorf("word %d is empty", i+1)
+		}
+		if n := utf8.RuneCountInString(word); n > MaxSize {
+			return nil, fmt.Errorf("word %d has %d letters, want at most %d", i+1, n, MaxSize)
+		}
+		wordsAndHints = append(wordsAndHints, &models.WordsAndHints{Word: w.Word, Hint: w.Hint})
+	}
+	for i, p := range req.Pinned {
+		n := utf8.RuneCountInString(strings.TrimSpace(p.Word))
+		if n > MaxSize {
+			return nil, fmt.Errorf("pinned word %d has %d letters, want at most %d", i+1, n, MaxSize)
+		}
+		dir, err := board.ParseDirection(p.Direction)
+		if err != nil {
+			return nil, fmt.Errorf("pinned word %d: %w", i+1, err)
+		}
+		endX, endY := p.X, p.Y
+		if dir == board.Across {
+			endX += n - 1
+		} else {
+			endY += n - 1
+		}
+		if p.X < 0 || p.Y < 0 || endX >= MaxSize || endY >= MaxSize {
+			return nil, fmt.Errorf("pinned word %d runs from (%d, %d) to (%d, %d), outside a board of %d cells a side", i+1, p.X, p.Y, endX, endY, MaxSize)
+		}
+	}
+	return wordsAndHints, nil
+}
+
+// objective returns the objective of the request, zero if it has none.
+func (req *Request) objective() (generators.Objective, error) {
+	if req.Objective == "" {
+		return generators.Objective{}, nil
+	}
+	return generators.ParseObjective(req.Objective)
+}
+
+// pinnedWords returns the pinned words of the request.
+func (req *Request) pinnedWords() []*models.PinnedWord {
+	var pinned []*models.PinnedWord
+	for _, p := range req.Pinned {
+		pinned = append(pinned, &models.PinnedWord{Word: p.Word, Hint: p.Hint, X: p.X, Y: p.Y, Direction: p.Direction})
+	}
+	return pinned
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
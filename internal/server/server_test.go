@@ -0,0 +1,217 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Germanicus1/crizzcrozz/internal/board"
+	"github.com/Germanicus1/crizzcrozz/internal/crossword"
+	"github.com/Germanicus1/crizzcrozz/internal/generators"
+	"github.com/Germanicus1/crizzcrozz/internal/server"
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+	"github.com/Germanicus1/crizzcrozz/pkg/models"
+)
+
+const haus = `{"words":[{"word":"haus","hint":"Gebäude"},{"word":"see","hint":"Stehendes Gewässer"}],"title":"Test"}`
+
+// solvedBuild returns a 5x3 board with "haus" across and "see" down.
+func solvedBuild(_ context.Context, _ []*models.WordsAndHints, _ crossword.Options) (*crossword.Result, error) {
+	bounds, err := board.NewBoundsRectangle(5, 3)
+	if err != nil {
+		return nil, err
+	}
+	b := board.NewBoard(bounds, 2, &board.OSFileWriter{})
+	b.PlaceEntryAt(board.Location{X: 0, Y: 0}, words.Entry{Word: "haus", Hint: "Gebäude"}, board.Across)
+	b.PlaceEntryAt(board.Location{X: 3, Y: 0}, words.Entry{Word: "see", Hint: "Stehendes Gewässer"}, board.Down)
+	b.SaveBestSolution()
+	return &crossword.Result{Board: b, Reason: generators.Complete}, nil
+}
+
+func post(t *testing.T, h http.Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/puzzles", strings.NewReader(body)))
+	return rec
+}
+
+func TestPuzzles(t *testing.T) {
+	s := server.New(time.Second, 1)
+	var got crossword.Options
+	s.Build = func(ctx context.Context, wh []*models.WordsAndHints, opts crossword.Options) (*crossword.Result, error) {
+		got = opts
+		return solvedBuild(ctx, wh, opts)
+	}
+
+	rec := post(t, s.Handler(), `{"words":[{"word":"haus","hint":"Gebäude"},{"word":"see","hint":"Stehendes Gewässer"}],
+		"size":7,"retries":3,"generator":"asymmetrical","seed":42,"objective":"compact","title":"Test"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Incorrect status, got: %d, want: %d (%s)", rec.Code, http.StatusOK, rec.Body)
+	}
+	want := crossword.Options{Width: 7, MaxRetries: 3, Generator: "asymmetrical", Seed: 42,
+		Objective: generators.Objectives["compact"], Budget: generators.Budget{MaxBacktracks: generators.DefaultMaxBacktracks, MaxNodes: server.DefaultMaxNodes}, Workers: 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Incorrect build options, got: %+v, want: %+v", got, want)
+	}
+
+	var res server.Response
+	if err := json.NewDecoder(rec.Body).Decode(&res); err != nil {
+		t.Fatalf("Invalid response: %s", err)
+	}
+	if res.Placed != 2 || res.Total != 2 || res.Reason != "complete" {
+		t.Errorf("Incorrect counts, got: %d/%d %s, want: 2/2 complete", res.Placed, res.Total, res.Reason)
+	}
+	if res.Puzzle.Title != "Test" || len(res.Puzzle.Across) != 1 || res.Puzzle.Across[0].Hint != "Gebäude" {
+		t.Errorf("Incorrect puzzle, got: %+v", res.Puzzle)
+	}
+}
+
+func TestPuzzles_BadRequest(t *testing.T) {
+	tests := map[string]string{
+		"invalid JSON":      `{"words":`,
+		"no words":          `{"words":[]}`,
+		"empty word":        `{"words":[{"word":" ","hint":"x"}]}`,
+		"negative size":     `{"words":[{"word":"haus"}],"size":-1}`,
+		"size too large":    `{"words":[{"word":"haus"}],"size":1000000}`,
+		"negative height":   `{"words":[{"word":"haus"}],"height":-1}`,
+		"height too large":  `{"words":[{"word":"haus"}],"height":1000000}`,
+		"negative retries":  `{"words":[{"word":"haus"}],"retries":-1}`,
+		"too many retries":  `{"words":[{"word":"haus"}],"retries":1000000}`,
+		"word too long":     `{"words":[{"word":"` + strings.Repeat("a", server.MaxSize+1) + `"}]}`,
+		"too many words":    `{"words":[` + strings.Repeat(`{"word":"haus"},`, server.MaxWords) + `{"word":"see"}]}`,
+		"unknown field":     `{"words":[{"word":"haus"}],"colour":"red"}`,
+		"pin outside board": `{"pinned":[{"word":"haus","x":1000000,"y":0,"direction":"across"}]}`,
+		"negative pin":      `{"pinned":[{"word":"haus","x":-1,"y":0,"direction":"across"}]}`,
+		"pin runs across":   `{"pinned":[{"word":"haus","x":` + strconv.Itoa(server.MaxSize-3) + `,"y":0,"direction":"across"}]}`,
+		"pin runs down":     `{"pinned":[{"word":"haus","x":0,"y":` + strconv.Itoa(server.MaxSize-3) + `,"direction":"down"}]}`,
+		"pin direction":     `{"pinned":[{"word":"haus","x":0,"y":0,"direction":"up"}]}`,
+		"unknown generator": `{"words":[{"word":"haus"}],"generator":"nope"}`,
+		"unknown objective": `{"words":[{"word":"haus"}],"objective":"nope"}`,
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			// The real builder knows the generators.
+			s := server.New(time.Second, 1)
+			rec := post(t, s.Handler(), body)
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("Incorrect status, got: %d, want: %d", rec.Code, http.StatusBadRequest)
+			}
+			if !strings.Contains(rec.Body.String(), `"error"`) {
+				t.Errorf("Expected an error message, got: %s", rec.Body)
+			}
+		})
+	}
+}
+
+func TestPuzzles_PinnedOnly(t *testing.T) {
+	// The real builder places the pinned words, which need no list of words.
+	s := server.New(time.Second, 1)
+	rec := post(t, s.Handler(), `{"pinned":[{"word":"haus","hint":"Gebäude","x":0,"y":1,"direction":"across"},
+		{"word":"see","hint":"Stehendes Gewässer","x":3,"y":1,"direction":"down"}],"size":5}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Incorrect status, got: %d, want: %d (%s)", rec.Code, http.StatusOK, rec.Body)
+	}
+	var res server.Response
+	if err := json.NewDecoder(rec.Body).Decode(&res); err != nil {
+		t.Fatalf("Invalid response: %s", err)
+	}
+	if res.Placed != 2 || res.Total != 2 || res.Reason != "complete" {
+		t.Errorf("Incorrect counts, got: %d/%d %s, want: 2/2 complete", res.Placed, res.Total, res.Reason)
+	}
+}
+
+func TestPuzzles_NoSolution(t *testing.T) {
+	s := server.New(time.Second, 1)
+	s.Build = func(_ context.Context, _ []*models.WordsAndHints, _ crossword.Options) (*crossword.Result, error) {
+		return nil, crossword.ErrNoWordsPlaced
+	}
+	if rec := post(t, s.Handler(), haus); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Incorrect status, got: %d, want: %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestPuzzles_Timeout(t *testing.T) {
+	s := server.New(20*time.Millisecond, 1)
+	s.Build = func(ctx context.Context, _ []*models.WordsAndHints, _ crossword.Options) (*crossword.Result, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if rec := post(t, s.Handler(), haus); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Incorrect status, got: %d, want: %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestPuzzles_ConcurrencyLimit(t *testing.T) {
+	const limit = 2
+	started := make(chan struct{}, limit+1)
+	release := make(chan struct{})
+
+	s := server.New(time.Second, limit)
+	s.Build = func(ctx context.Context, wh []*models.WordsAndHints, opts crossword.Options) (*crossword.Result, error) {
+		started <- struct{}{}
+		<-release
+		return solvedBuild(ctx, wh, opts)
+	}
+	h := s.Handler()
+
+	var wg sync.WaitGroup
+	codes := make(chan int, limit)
+	for i := 0; i < limit; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- post(t, h, haus).Code
+		}()
+	}
+	for i := 0; i < limit; i++ {
+		<-started
+	}
+
+	// All slots are taken, so the next request waits until it times out.
+	s.Timeout = 20 * time.Millisecond
+	if rec := post(t, h, haus); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Incorrect status over the limit, got: %d, want: %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	if len(started) != 0 {
+		t.Errorf("Expected no generation over the limit")
+	}
+
+	close(release)
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("Incorrect status within the limit, got: %d, want: %d", code, http.StatusOK)
+		}
+	}
+}
+
+func TestPuzzles_Partial(t *testing.T) {
+	s := server.New(time.Second, 1)
+	s.Build = func(_ context.Context, _ []*models.WordsAndHints, _ crossword.Options) (*crossword.Result, error) {
+		bounds, _ := board.NewBoundsRectangle(5, 3)
+		b := board.NewBoard(bounds, 2, &board.OSFileWriter{})
+		b.PlaceEntryAt(board.Location{X: 0, Y: 0}, words.Entry{Word: "haus", Hint: "Gebäude"}, board.Across)
+		b.SaveBestSolution()
+		return &crossword.Result{Board: b, Reason: generators.BudgetExceeded}, nil
+	}
+
+	rec := post(t, s.Handler(), haus)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Incorrect status, got: %d, want: %d (%s)", rec.Code, http.StatusOK, rec.Body)
+	}
+	var res server.Response
+	if err := json.NewDecoder(rec.Body).Decode(&res); err != nil {
+		t.Fatalf("Invalid response: %s", err)
+	}
+	if res.Placed != 1 || res.Total != 2 || res.Reason != "budget_exceeded" {
+		t.Errorf("Incorrect result, got: %d/%d %s, want: 1/2 budget_exceeded", res.Placed, res.Total, res.Reason)
+	}
+}
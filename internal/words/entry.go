@@ -0,0 +1,13 @@
+package words
+
+// Entry is a word to be placed on the board together with its hint. The
+// same word may appear in several entries with different hints.
+type Entry struct {
+	Word string // The answer as written into the grid.
+	Hint string // The clue text shown to the player.
+}
+
+// Length returns the number of letters (not bytes) in the word.
+func (e Entry) Length() int {
+	return len([]rune(e.Word))
+}
@@ -1,27 +1,37 @@
-package models
+package words
 
 type Pool struct {
-	Words    []string
-	ByLength map[int][]string
+	Entries  []Entry
+	ByLength map[int][]Entry
 	WordSet  map[string]bool
 }
 
 func NewPool() *Pool {
 	return &Pool{
-		ByLength: make(map[int][]string),
+		ByLength: make(map[int][]Entry),
 		WordSet:  make(map[string]bool),
 	}
 }
 
-// LoadWords loads words into the pool from a given slice of words.
+// LoadEntries loads words and their hints into the pool, keeping their
+// order.
+func (p *Pool) LoadEntries(entries []Entry) {
+	for _, entry := range entries {
+		length := entry.Length()
+		p.Entries = append(p.Entries, entry)
+		p.ByLength[length] = append(p.ByLength[length], entry)
+		p.WordSet[entry.Word] = true // Add the word to the set for quick validation
+	}
+}
 
+// LoadWords loads words without hints into the pool from a given slice of
+// words.
 func (p *Pool) LoadWords(words []string) {
-	for _, word := range words {
-		length := len([]rune(word))
-		p.Words = append(p.Words, word)
-		p.ByLength[length] = append(p.ByLength[length], word)
-		p.WordSet[word] = true // Add the word to the set for quick validation
+	entries := make([]Entry, len(words))
+	for i, word := range words {
+		entries[i] = Entry{Word: word}
 	}
+	p.LoadEntries(entries)
 }
 
 // Exists checks if a word is in the pool.
@@ -29,3 +39,10 @@ func (p *Pool) Exists(word string) bool {
 	_, exists := p.WordSet[word]
 	return exists
 }
+
+// Clone returns an independent copy of the pool.
+func (p *Pool) Clone() *Pool {
+	clone := NewPool()
+	clone.LoadEntries(p.Entries)
+	return clone
+}
@@ -0,0 +1,12 @@
+package words
+
+import "sort"
+
+// SortByLength sorts entries in place from the longest to the shortest word
+// and returns them. Entries of equal length keep their order.
+func SortByLength(entries []Entry) []Entry {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Length() > entries[j].Length() // Sorts words by length.
+	})
+	return entries
+}
@@ -0,0 +1,31 @@
+package words_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Germanicus1/crizzcrozz/internal/words"
+)
+
+func TestSortByLength(t *testing.T) {
+	list := []words.Entry{
+		{Word: "zoo"},
+		{Word: "österreich"},
+		{Word: "bank", Hint: "Sitzmöbel"},
+		{Word: "ärger"},
+		{Word: "bank", Hint: "Geldinstitut"},
+		{Word: "überraschung"},
+	}
+	result := words.SortByLength(list)
+	want := []words.Entry{
+		{Word: "überraschung"},
+		{Word: "österreich"},
+		{Word: "ärger"},
+		{Word: "bank", Hint: "Sitzmöbel"},
+		{Word: "bank", Hint: "Geldinstitut"},
+		{Word: "zoo"},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("Incorrect result, got: %v, want: %v", result, want)
+	}
+}
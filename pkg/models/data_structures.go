@@ -6,3 +6,14 @@ type WordsAndHints struct {
 	Word string `csv:"word"`
 	Hint string `csv:"hint"`
 }
+
+// PinnedWord defines a struct for mapping words placed by hand from a CSV
+// file. X and Y are the column and row of the first letter, counted from 0,
+// and Direction is across or down.
+type PinnedWord struct {
+	Word      string `csv:"word"`
+	Hint      string `csv:"hint"`
+	X         int    `csv:"x"`
+	Y         int    `csv:"y"`
+	Direction string `csv:"direction"`
+}